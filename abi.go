@@ -0,0 +1,262 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"text/template"
+)
+
+var (
+	reCIndexVersionMajor = regexp.MustCompile(`(?m)^#define\s+CINDEX_VERSION_MAJOR\s+(\d+)`)
+	reCIndexVersionMinor = regexp.MustCompile(`(?m)^#define\s+CINDEX_VERSION_MINOR\s+(\d+)`)
+	reLLVMVersion        = regexp.MustCompile(`^(\d+)\.(\d+)`)
+)
+
+// ParseCIndexVersion parses CINDEX_VERSION_MAJOR and CINDEX_VERSION_MINOR from the content of Index.h.
+func ParseCIndexVersion(header []byte) (major int, minor int, err error) {
+	mMajor := reCIndexVersionMajor.FindSubmatch(header)
+	mMinor := reCIndexVersionMinor.FindSubmatch(header)
+	if mMajor == nil || mMinor == nil {
+		return 0, 0, errors.New("cannot find CINDEX_VERSION_MAJOR and CINDEX_VERSION_MINOR")
+	}
+
+	if major, err = strconv.Atoi(string(mMajor[1])); err != nil {
+		return 0, 0, err
+	}
+	if minor, err = strconv.Atoi(string(mMinor[1])); err != nil {
+		return 0, 0, err
+	}
+
+	return major, minor, nil
+}
+
+var templateGenerateABIFile = template.Must(template.New("go-clang-generate-abi-file").Parse(`package clang
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// CIndexVersionMajor is the CINDEX_VERSION_MAJOR of the headers the bindings were generated from.
+	CIndexVersionMajor = {{$.CIndexVersionMajor}}
+	// CIndexVersionMinor is the CINDEX_VERSION_MINOR of the headers the bindings were generated from.
+	CIndexVersionMinor = {{$.CIndexVersionMinor}}
+	// LLVMVersion is the version of the LLVM the bindings were generated from.
+	LLVMVersion = "{{$.LLVMVersion}}"
+)
+
+const (
+	llvmVersionMajor = {{$.LLVMVersionMajor}}
+	llvmVersionMinor = {{$.LLVMVersionMinor}}
+)
+
+// cindexSymbols holds the libclang functions which are called by the bindings. libclang adds functions with every
+// CINDEX_VERSION_MINOR, so a library which lacks one of them implements an older CINDEX_VERSION.
+var cindexSymbols = []string{
+{{range $s := $.Symbols}}	"{{$s}}",
+{{end}}}
+
+// checkCIndexSymbols checks that the loaded libclang, whose symbols are looked up with lookup, defines all functions of
+// the CINDEX_VERSION of the headers the bindings were generated from.
+func checkCIndexSymbols(lookup func(symbol string) bool) error {
+	for _, s := range cindexSymbols {
+		if !lookup(s) {
+			return fmt.Errorf("the loaded libclang lacks %s and is therefore older than CINDEX_VERSION %d.%d which the bindings were generated from", s, CIndexVersionMajor, CIndexVersionMinor)
+		}
+	}
+
+	return nil
+}
+
+var reClangVersion = regexp.MustCompile(` + "`" + `(\d+)\.(\d+)` + "`" + `)
+
+// checkClangVersion checks that the libclang with the clang_getClangVersion v is not older than the headers the
+// bindings were generated from.
+func checkClangVersion(v string) error {
+	m := reClangVersion.FindStringSubmatch(v)
+	if m == nil {
+		return fmt.Errorf("cannot parse the version %q of the loaded libclang", v)
+	}
+
+	major, err := strconv.Atoi(m[1])
+	if err != nil {
+		return fmt.Errorf("cannot parse the version %q of the loaded libclang: %w", v, err)
+	}
+	minor, err := strconv.Atoi(m[2])
+	if err != nil {
+		return fmt.Errorf("cannot parse the version %q of the loaded libclang: %w", v, err)
+	}
+
+	if major < llvmVersionMajor || (major == llvmVersionMajor && minor < llvmVersionMinor) {
+		return fmt.Errorf("the loaded libclang %d.%d is older than LLVM %s with CINDEX_VERSION %d.%d which the bindings were generated from", major, minor, LLVMVersion, CIndexVersionMajor, CIndexVersionMinor)
+	}
+
+	return nil
+}
+`))
+
+var templateGenerateABICgoFile = template.Must(template.New("go-clang-generate-abi-cgo-file").Parse(`package clang
+
+// CheckABI checks that the loaded libclang is not older than the headers the bindings were generated from.
+//
+// libclang only adds symbols in newer versions, i.e. the CINDEX_VERSION_MINOR of the headers, so calling a function of
+// an older library than the one the bindings were generated for can fail with a missing symbol. CheckABI therefore
+// checks that the library defines every function of the bindings. libclang does not expose its CINDEX_VERSION, so
+// CheckABI additionally checks that the LLVM version which clang_getClangVersion reports is not older than
+// LLVMVersion.
+func CheckABI() error {
+	// the symbols of a statically linked libclang are not exported by a shared library
+	if hasSymbol("clang_getClangVersion") {
+		if err := checkCIndexSymbols(hasSymbol); err != nil {
+			return err
+		}
+	}
+
+	return checkClangVersion(GetClangVersion())
+}
+`))
+
+var templateGenerateABISymbolFile = template.Must(template.New("go-clang-generate-abi-symbol-file").Parse(`package clang
+
+// #cgo linux LDFLAGS: -ldl
+// #define _GNU_SOURCE
+// #include <dlfcn.h>
+// #include <stdlib.h>
+//
+// static int go_clang_has_symbol(const char *symbol) {
+// 	return dlsym(RTLD_DEFAULT, symbol) != NULL;
+// }
+import "C"
+
+import "unsafe"
+
+// hasSymbol reports whether symbol is defined by a loaded shared library.
+func hasSymbol(symbol string) bool {
+	s := C.CString(symbol)
+	defer C.free(unsafe.Pointer(s))
+
+	return C.go_clang_has_symbol(s) != 0
+}
+`))
+
+var templateGenerateABISymbolWindowsFile = template.Must(template.New("go-clang-generate-abi-symbol-windows-file").Parse(`package clang
+
+// #include <stdlib.h>
+// #include <windows.h>
+// #include "{{$.UmbrellaHeader}}"
+//
+// static int go_clang_has_symbol(const char *symbol) {
+// 	HMODULE module;
+// 	if (!GetModuleHandleExA(GET_MODULE_HANDLE_EX_FLAG_FROM_ADDRESS | GET_MODULE_HANDLE_EX_FLAG_UNCHANGED_REFCOUNT, (LPCSTR)&clang_getClangVersion, &module)) {
+// 		return 0;
+// 	}
+//
+// 	return GetProcAddress(module, symbol) != NULL;
+// }
+import "C"
+
+import "unsafe"
+
+// hasSymbol reports whether symbol is exported by the module which defines clang_getClangVersion.
+func hasSymbol(symbol string) bool {
+	s := C.CString(symbol)
+	defer C.free(unsafe.Pointer(s))
+
+	return C.go_clang_has_symbol(s) != 0
+}
+`))
+
+// abiSymbols returns the sorted C functions of g which are exported symbols of libclang.
+func (g *Generation) abiSymbols() []string {
+	seen := make(map[string]bool)
+
+	var symbols []string
+	for _, f := range g.functions {
+		// static inline and variadic functions are called through shims, global variables are no functions
+		if f.IsInline || f.IsVariadic || f.Shim != "" || f.Variable != nil || seen[f.CName] {
+			continue
+		}
+
+		seen[f.CName] = true
+		symbols = append(symbols, f.CName)
+	}
+
+	sort.Strings(symbols)
+
+	return symbols
+}
+
+// hasABIGuard reports whether the ABI guard is generated, which needs the LLVM version and Index.h.
+func (g *Generation) hasABIGuard() bool {
+	return g.api.LLVMVersion != "" && g.indexHeader != ""
+}
+
+// abiSymbolOutput returns the output of the symbol lookup of CheckABI for the operating systems which match the build
+// constraint c. The lookup uses cgo, like CheckABI.
+func (g *Generation) abiSymbolOutput(c string) Output {
+	if g.api.Backend == BackendDlopen {
+		c = "cgo && " + c
+	}
+
+	return g.constrainedOutput(c)
+}
+
+// GenerateABIGuard generates the CheckABI function which compares the loaded libclang with the headers the bindings are
+// generated from. The CINDEX_VERSION of the library is checked by looking up every function of the bindings with dlsym,
+// or GetProcAddress on Windows, and the LLVM version of clang_getClangVersion is compared with API.LLVMVersion. The CheckABI function of the dlopen backend is generated by
+// GenerateDlopen.
+func (g *Generation) GenerateABIGuard() error {
+	m := reLLVMVersion.FindStringSubmatch(g.api.LLVMVersion)
+	if m == nil {
+		return fmt.Errorf("cannot parse LLVM version %q", g.api.LLVMVersion)
+	}
+
+	header, err := os.ReadFile(g.indexHeader)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", g.indexHeader, err)
+	}
+
+	cindexMajor, cindexMinor, err := ParseCIndexVersion(header)
+	if err != nil {
+		return fmt.Errorf("cannot parse CINDEX_VERSION of %s: %w", g.indexHeader, err)
+	}
+
+	data := map[string]interface{}{
+		"CIndexVersionMajor": cindexMajor,
+		"CIndexVersionMinor": cindexMinor,
+		"LLVMVersion":        g.api.LLVMVersion,
+		"LLVMVersionMajor":   m[1],
+		"LLVMVersionMinor":   m[2],
+		"Symbols":            g.abiSymbols(),
+		"UmbrellaHeader":     g.umbrellaHeader(),
+	}
+
+	for _, f := range []struct {
+		name string
+		tmpl *template.Template
+		out  Output
+	}{
+		{"abi_gen.go", templateGenerateABIFile, g.output()},
+		{"abi_cgo_gen.go", templateGenerateABICgoFile, g.cgoOutput()},
+		{"abi_symbol_gen.go", templateGenerateABISymbolFile, g.abiSymbolOutput("!windows")},
+		{"abi_symbol_windows_gen.go", templateGenerateABISymbolWindowsFile, g.abiSymbolOutput("windows")},
+	} {
+		var b bytes.Buffer
+		if err := f.tmpl.Execute(&b, data); err != nil {
+			return err
+		}
+
+		if err := writeGoFile(f.out, f.name, b.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/go-clang/gen"
+)
+
+func TestParseCIndexVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		header    string
+		wantMajor int
+		wantMinor int
+		wantErr   bool
+	}{
+		"Index.h": {
+			header: `#define CINDEX_VERSION_MAJOR 0
+#define CINDEX_VERSION_MINOR 62
+
+#define CINDEX_VERSION_ENCODE(major, minor) (((major)*10000) + ((minor)*1))`,
+			wantMajor: 0,
+			wantMinor: 62,
+		},
+		"missing minor": {
+			header:  `#define CINDEX_VERSION_MAJOR 0`,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			major, minor, err := gen.ParseCIndexVersion([]byte(tt.header))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCIndexVersion() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if major != tt.wantMajor || minor != tt.wantMinor {
+				t.Fatalf("ParseCIndexVersion() = %d.%d, want %d.%d", major, minor, tt.wantMajor, tt.wantMinor)
+			}
+		})
+	}
+}
+
+func TestGeneration_GenerateABIGuard(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		backend gen.Backend
+		// want maps the generated files to their golden files.
+		want map[string]string
+	}{
+		"cgo": {
+			backend: gen.BackendCgo,
+			want: map[string]string{
+				"abi_gen.go":                "abi/abi_gen.go",
+				"abi_cgo_gen.go":            "abi/abi_cgo_gen.go",
+				"abi_symbol_gen.go":         "abi/abi_symbol_gen.go",
+				"abi_symbol_windows_gen.go": "abi/abi_symbol_windows_gen.go",
+			},
+		},
+		"dlopen": {
+			backend: gen.BackendDlopen,
+			want: map[string]string{
+				"abi_gen.go":                "abi/abi_gen.go",
+				"abi_cgo_gen.go":            "abi/abi_cgo_dlopen_gen.go",
+				"abi_symbol_gen.go":         "abi/abi_symbol_dlopen_gen.go",
+				"abi_symbol_windows_gen.go": "abi/abi_symbol_windows_dlopen_gen.go",
+			},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := gen.NewMemoryOutput()
+			api := &gen.API{Backend: tt.backend, LLVMVersion: "15.0.7", Output: out}
+
+			h := gen.NewHeaderFile(api, "Index.h", "testdata/abi")
+			h.Functions = []*gen.Function{
+				{Name: "DisposeIndex", CName: "clang_disposeIndex"},
+				{Name: "CreateIndex", CName: "clang_createIndex"},
+				{Name: "CreateIndex", CName: "clang_createIndex"},
+				{Name: "IsNull", CName: "clang_Cursor_isNull", IsInline: true, Shim: "go_clang_Cursor_isNull"},
+				{Name: "Format", CName: "clang_format", IsVariadic: true},
+			}
+
+			g := gen.NewGeneration(api)
+			g.AddHeaderFiles([]*gen.HeaderFile{h})
+
+			if err := g.GenerateABIGuard(); err != nil {
+				t.Fatalf("GenerateABIGuard() error = %v", err)
+			}
+
+			for file, golden := range tt.want {
+				checkGolden(t, golden, out.Files()[file])
+			}
+			if len(out.Files()) != len(tt.want) {
+				t.Fatalf("GenerateABIGuard() generated %d files, want %d", len(out.Files()), len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package gen
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// sliceAccessor represents a pair of a count function and an index getter of a struct which are combined into slice
+// and iterator accessors.
+type sliceAccessor struct {
+	Name         string
+	Receiver     string
+	ReceiverType string
+	Element      string
+	Num          string
+	Getter       string
+	Index        string
+	// Out holds the Go type of the out-parameter which the getter returns before the element, or is empty.
+	Out string
+}
+
+var templateGenerateSliceAccessor = template.Must(template.New("go-clang-generate-slice-accessor").Parse(`{{if $.Out}}// All{{$.Name}} returns an iterator over all values of {{$.Getter}}, see {{$.Num}}.
+func ({{$.Receiver}} {{$.ReceiverType}}) All{{$.Name}}() iter.Seq2[{{$.Out}}, {{$.Element}}] {
+	return func(yield func({{$.Out}}, {{$.Element}}) bool) {
+		n := int({{$.Receiver}}.{{$.Num}}())
+		for i := 0; i < n; i++ {
+			if !yield({{$.Receiver}}.{{$.Getter}}({{$.Index}}(i))) {
+				return
+			}
+		}
+	}
+}{{else}}// {{$.Name}} returns all values of {{$.Getter}}, see {{$.Num}}.
+func ({{$.Receiver}} {{$.ReceiverType}}) {{$.Name}}() []{{$.Element}} {
+	n := int({{$.Receiver}}.{{$.Num}}())
+	if n <= 0 {
+		return nil
+	}
+
+	s := make([]{{$.Element}}, n)
+	for i := range s {
+		s[i] = {{$.Receiver}}.{{$.Getter}}({{$.Index}}(i))
+	}
+
+	return s
+}
+
+// All{{$.Name}} returns an iterator over all values of {{$.Getter}}, see {{$.Num}}.
+func ({{$.Receiver}} {{$.ReceiverType}}) All{{$.Name}}() iter.Seq[{{$.Element}}] {
+	return func(yield func({{$.Element}}) bool) {
+		n := int({{$.Receiver}}.{{$.Num}}())
+		for i := 0; i < n; i++ {
+			if !yield({{$.Receiver}}.{{$.Getter}}({{$.Index}}(i))) {
+				return
+			}
+		}
+	}
+}{{end}}`))
+
+// singularWords holds the singulars of plural words which are not formed by the suffix rules of singularWord.
+var singularWords = map[string]string{
+	"Aliases":  "Alias",
+	"Analyses": "Analysis",
+	"Bases":    "Base",
+	"Children": "Child",
+	"Indices":  "Index",
+	"Statuses": "Status",
+}
+
+// singularWord returns the singular of word and reports whether word is a plural.
+func singularWord(word string) (string, bool) {
+	if s, ok := singularWords[word]; ok {
+		return s, true
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word, false
+
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y", true
+
+	case strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"):
+		return strings.TrimSuffix(word, "es"), true
+
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s"), true
+	}
+
+	return word, false
+}
+
+// SingularName returns the singular of the plural name which is used by a count function, e.g. "Diagnostics" for
+// "NumDiagnostics" or "DiagnosticsInSet" for "NumDiagnosticsInSet". The last plural word is made singular, irregular
+// plurals like "Children" are looked up in singularWords.
+func SingularName(name string) string {
+	r := []rune(name)
+	for end := len(r); end > 0; {
+		start := end - 1
+		for start > 0 && !unicode.IsUpper(r[start]) {
+			start--
+		}
+
+		if s, ok := singularWord(string(r[start:end])); ok {
+			return string(r[:start]) + s + string(r[end:])
+		}
+
+		end = start
+	}
+
+	return name
+}
+
+// sliceAccessorElementType returns the Go type of the values returned by getter and reports whether it is supported.
+func sliceAccessorElementType(getter *Function) (string, bool) {
+	typ := getter.ReturnType
+
+	switch {
+	case typ.PointerLevel == 1 && typ.CGoName == CSChar:
+		return "string", true
+
+	case typ.PointerLevel > 0 || typ.IsSlice || typ.GoName == "void" || typ.GoName == "":
+		return "", false
+
+	case typ.GoName == "cxstring":
+		return "string", true
+	}
+
+	return typ.GoName, true
+}
+
+// indexGetter reports whether f returns a value of receiver for an index and returns the out-parameters which follow
+// the index, e.g. the range of clang_getDiagnosticFixIt.
+func indexGetter(f *Function, receiver string) ([]FunctionParameter, bool) {
+	if f.Member != nil || len(f.Parameters) < 2 || f.Parameters[0].Type.GoName != receiver {
+		return nil, false
+	}
+
+	index := f.Parameters[1].Type
+	if !IsInteger(&index) || index.PointerLevel != 0 || index.IsSlice || index.IsReturnArgument {
+		return nil, false
+	}
+
+	out := f.Parameters[2:]
+	for _, p := range out {
+		if !p.Type.IsReturnArgument {
+			return nil, false
+		}
+	}
+
+	return out, true
+}
+
+// sliceAccessorOutType returns the Go type which is returned for the out-parameter p and reports whether it is
+// supported.
+func sliceAccessorOutType(p FunctionParameter) (string, bool) {
+	typ := p.Type
+
+	switch {
+	case typ.IsSlice || typ.LengthOfSlice != "" || typ.PointerLevel != 1 || typ.GoName == "void" || typ.GoName == "":
+		return "", false
+
+	case typ.GoName == "cxstring":
+		return "string", true
+	}
+
+	return typ.GoName, true
+}
+
+// AddSliceAccessors adds slice and iterator accessors to s for every count function "NumX" which has matching index
+// getters, e.g. "Diagnostics" and "AllDiagnostics" for "NumDiagnostics" and "Diagnostic(i)". Getters which return an
+// out-parameter before their value, e.g. "FixIt(i) (SourceRange, string)", only get an iterator over both values since
+// a slice cannot hold them. Getters with more out-parameters, and nullable getters which also return whether their
+// value is not NULL, are added to the report. Functions whose names are not
+// unique yet are not combined either, since ResolveNameConflicts may rename them after all methods were added.
+func (g *Generation) AddSliceAccessors(s *Struct) error {
+	receiverType := s.Name
+	if s.IsPointerComposition {
+		receiverType = "*" + receiverType
+	}
+
+	functions := map[string]int{}
+	for _, m := range s.Methods {
+		if f, ok := m.(*Function); ok {
+			functions[f.Name]++
+		}
+	}
+
+	var accessors []sliceAccessor
+
+	for _, m := range s.Methods {
+		num, ok := m.(*Function)
+		if !ok || functions[num.Name] > 1 || !strings.HasPrefix(num.Name, "Num") || !IsInteger(&num.ReturnType) {
+			continue
+		}
+		if len(num.Parameters) > 1 || (len(num.Parameters) == 1 && num.Parameters[0].Type.GoName != s.Name) {
+			continue
+		}
+
+		plural := strings.TrimPrefix(num.Name, "Num")
+		singular := SingularName(plural)
+		if singular == plural {
+			continue
+		}
+
+		for _, m := range s.Methods {
+			getter, ok := m.(*Function)
+			if !ok || functions[getter.Name] > 1 || !strings.HasPrefix(getter.Name, singular) {
+				continue
+			}
+
+			outs, ok := indexGetter(getter, s.Name)
+			if !ok {
+				continue
+			}
+
+			element, ok := sliceAccessorElementType(getter)
+			if !ok {
+				continue
+			}
+
+			var out string
+			if g.isNullableFunction(getter) {
+				g.report.Add(ReportSkipped, getter.CName, "nullable index getter is not combined with %s", num.CName)
+
+				continue
+			} else if len(outs) > 1 {
+				g.report.Add(ReportSkipped, getter.CName, "index getter with %d out-parameters is not combined with %s", len(outs), num.CName)
+
+				continue
+			} else if len(outs) == 1 {
+				if out, ok = sliceAccessorOutType(outs[0]); !ok {
+					g.report.Add(ReportSkipped, getter.CName, "index getter with out-parameter of type %q is not combined with %s", outs[0].Type.CName, num.CName)
+
+					continue
+				}
+			}
+
+			name := plural
+			if getter.Name != singular {
+				name = getter.Name + "s"
+			}
+
+			accessors = append(accessors, sliceAccessor{
+				Name:         name,
+				Receiver:     s.Receiver.Name,
+				ReceiverType: receiverType,
+				Element:      element,
+				Num:          num.Name,
+				Getter:       getter.Name,
+				Index:        getter.Parameters[1].Type.GoName,
+				Out:          out,
+			})
+		}
+	}
+
+	for _, a := range accessors {
+		if (a.Out == "" && s.ContainsMethod(a.Name)) || s.ContainsMethod("All"+a.Name) {
+			continue
+		}
+
+		var b bytes.Buffer
+		if err := templateGenerateSliceAccessor.Execute(&b, a); err != nil {
+			return err
+		}
+
+		s.Methods = append(s.Methods, b.String())
+	}
+
+	return nil
+}
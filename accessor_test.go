@@ -0,0 +1,209 @@
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestSingularName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		name string
+		want string
+	}{
+		"Diagnostics":       {name: "Diagnostics", want: "Diagnostic"},
+		"DiagnosticsInSet":  {name: "DiagnosticsInSet", want: "DiagnosticInSet"},
+		"TemplateArguments": {name: "TemplateArguments", want: "TemplateArgument"},
+		"Children":          {name: "Children", want: "Child"},
+		"Args":              {name: "Args", want: "Arg"},
+		"Elements":          {name: "Elements", want: "Element"},
+		"Size":              {name: "Size", want: "Size"},
+		"Status":            {name: "Status", want: "Status"},
+		"Ranges":            {name: "Ranges", want: "Range"},
+		"Aliases":           {name: "Aliases", want: "Alias"},
+		"Properties":        {name: "Properties", want: "Property"},
+		"Matches":           {name: "Matches", want: "Match"},
+		"OverloadedDecls":   {name: "OverloadedDecls", want: "OverloadedDecl"},
+		"IndicesInStatus":   {name: "IndicesInStatus", want: "IndexInStatus"},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := gen.SingularName(tt.name); got != tt.want {
+				t.Fatalf("SingularName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneration_AddSliceAccessors(t *testing.T) {
+	t.Parallel()
+
+	diagnostic := gen.Type{CName: "CXDiagnostic", CGoName: "CXDiagnostic", GoName: "Diagnostic"}
+	index := gen.Type{CName: "unsigned int", CGoName: gen.CUInt, GoName: gen.GoUInt32}
+	sourceRange := gen.Type{CName: "CXSourceRange *", CGoName: "CXSourceRange", GoName: "SourceRange", PointerLevel: 1, IsReturnArgument: true}
+
+	s := &gen.Struct{
+		Name:     "Diagnostic",
+		CName:    "CXDiagnostic",
+		Receiver: gen.Receiver{Name: "d", Type: diagnostic},
+		Methods: []interface{}{
+			&gen.Function{
+				Name:       "NumRanges",
+				CName:      "clang_getDiagnosticNumRanges",
+				Parameters: []gen.FunctionParameter{{Name: "d", Type: diagnostic}},
+				ReturnType: index,
+			},
+			&gen.Function{
+				Name:       "Range",
+				CName:      "clang_getDiagnosticRange",
+				Parameters: []gen.FunctionParameter{{Name: "d", Type: diagnostic}, {Name: "r", Type: index}},
+				ReturnType: gen.Type{CName: "CXSourceRange", CGoName: "CXSourceRange", GoName: "SourceRange"},
+			},
+			&gen.Function{
+				Name:       "NumFixIts",
+				CName:      "clang_getDiagnosticNumFixIts",
+				Parameters: []gen.FunctionParameter{{Name: "d", Type: diagnostic}},
+				ReturnType: index,
+			},
+			&gen.Function{
+				Name:  "FixIt",
+				CName: "clang_getDiagnosticFixIt",
+				Parameters: []gen.FunctionParameter{
+					{Name: "d", Type: diagnostic},
+					{Name: "fixIt", Type: index},
+					{Name: "replacementRange", Type: sourceRange},
+				},
+				ReturnType: gen.Type{CName: "CXString", CGoName: "cxstring", GoName: "cxstring"},
+			},
+			&gen.Function{
+				Name:  "FixItRanges",
+				CName: "clang_getDiagnosticFixItRanges",
+				Parameters: []gen.FunctionParameter{
+					{Name: "d", Type: diagnostic},
+					{Name: "fixIt", Type: index},
+					{Name: "begin", Type: sourceRange},
+					{Name: "end", Type: sourceRange},
+				},
+				ReturnType: gen.Type{CName: "CXString", CGoName: "cxstring", GoName: "cxstring"},
+			},
+			&gen.Function{
+				Name:       "NumStatus",
+				CName:      "clang_getDiagnosticNumStatus",
+				Parameters: []gen.FunctionParameter{{Name: "d", Type: diagnostic}},
+				ReturnType: index,
+			},
+			&gen.Function{
+				Name:       "Statu",
+				CName:      "clang_getDiagnosticStatu",
+				Parameters: []gen.FunctionParameter{{Name: "d", Type: diagnostic}, {Name: "i", Type: index}},
+				ReturnType: index,
+			},
+		},
+	}
+
+	g := gen.NewGeneration(&gen.API{})
+	if err := g.AddSliceAccessors(s); err != nil {
+		t.Fatalf("AddSliceAccessors() error = %v", err)
+	}
+
+	var accessors []string
+	for _, m := range s.Methods {
+		if src, ok := m.(string); ok {
+			accessors = append(accessors, src)
+		}
+	}
+	checkGolden(t, "accessor/diagnostic.go", []byte(strings.Join(accessors, "\n\n")+"\n"))
+
+	want := []gen.ReportEntry{
+		{Kind: gen.ReportSkipped, Symbol: "clang_getDiagnosticFixItRanges", Message: "index getter with 2 out-parameters is not combined with clang_getDiagnosticNumFixIts"},
+	}
+	if diff := cmp.Diff(want, g.Report().Entries); diff != "" {
+		t.Fatalf("AddSliceAccessors() report: (-want +got):\n%s", diff)
+	}
+}
+
+func TestGeneration_AddSliceAccessorsNullable(t *testing.T) {
+	t.Parallel()
+
+	translationUnit := gen.Type{CName: "CXTranslationUnit", CGoName: "CXTranslationUnit", GoName: "TranslationUnit"}
+	index := gen.Type{CName: "unsigned int", CGoName: gen.CUInt, GoName: gen.GoUInt32}
+
+	tests := map[string]struct {
+		filter     func(f *gen.Function) bool
+		want       string
+		wantReport []gen.ReportEntry
+	}{
+		"nullable": {
+			wantReport: []gen.ReportEntry{
+				{Kind: gen.ReportSkipped, Symbol: "clang_getDiagnostic", Message: "nullable index getter is not combined with clang_getNumDiagnostics"},
+			},
+		},
+		"filtered": {
+			filter: func(f *gen.Function) bool { return f.Name != "Diagnostic" },
+			want:   "accessor/translationunit.go",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &gen.Struct{
+				Name:     "TranslationUnit",
+				CName:    "CXTranslationUnit",
+				Receiver: gen.Receiver{Name: "tu", Type: translationUnit},
+				Methods: []interface{}{
+					&gen.Function{
+						Name:       "NumDiagnostics",
+						CName:      "clang_getNumDiagnostics",
+						Parameters: []gen.FunctionParameter{{Name: "tu", Type: translationUnit}},
+						ReturnType: index,
+					},
+					&gen.Function{
+						Name:       "Diagnostic",
+						CName:      "clang_getDiagnostic",
+						Parameters: []gen.FunctionParameter{{Name: "tu", Type: translationUnit}, {Name: "index", Type: index}},
+						ReturnType: gen.Type{CName: "CXDiagnostic", CGoName: "CXDiagnostic", GoName: "Diagnostic"},
+					},
+				},
+			}
+
+			g := gen.NewGeneration(&gen.API{
+				NullHandling:           gen.NullHandlingOK,
+				FilterNullableFunction: tt.filter,
+			})
+			g.RegisterStruct(&gen.Struct{Name: "Diagnostic", CName: "CXDiagnostic", IsPointerTypedef: true})
+
+			if err := g.AddSliceAccessors(s); err != nil {
+				t.Fatalf("AddSliceAccessors() error = %v", err)
+			}
+
+			var accessors []string
+			for _, m := range s.Methods {
+				if src, ok := m.(string); ok {
+					accessors = append(accessors, src)
+				}
+			}
+
+			if tt.want == "" {
+				if len(accessors) != 0 {
+					t.Fatalf("AddSliceAccessors() added %d accessors, want none", len(accessors))
+				}
+			} else {
+				checkGolden(t, tt.want, []byte(strings.Join(accessors, "\n\n")+"\n"))
+			}
+
+			if diff := cmp.Diff(tt.wantReport, g.Report().Entries); diff != "" {
+				t.Fatalf("Report().Entries: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
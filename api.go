@@ -1,9 +1,12 @@
 package gen
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"text/template"
 )
 
 // API represents a Clang bindings generation.
@@ -14,6 +17,14 @@ type API struct {
 	// PrepareFunction prepares a function for further processing.
 	PrepareFunction func(f *Function)
 
+	// InferOutParameters marks pointer parameters as return arguments which are documented as outputs, in addition to
+	// the return arguments set by PrepareFunction. Ambiguous parameters are added to the report.
+	InferOutParameters bool
+
+	// VariadicShims holds fixed-arity signatures of variadic C functions keyed by their C names. Variadic functions
+	// without signatures are not generated.
+	VariadicShims map[string][]VariadicSignature
+
 	// FilterFunction determines if a function is generateable.
 	FilterFunction func(f *Function) bool
 
@@ -23,32 +34,141 @@ type API struct {
 	// FixFunctionName returns an unempty string if a function needs to receive a specific name.
 	FixFunctionName func(f *Function) string
 
+	// Initialisms holds the initialisms, e.g. "USR", which are applied to the generated Go identifiers. Nothing is
+	// renamed if it is empty.
+	Initialisms []string
+
+	// ResolveNameConflicts renames functions which map to the same Go name instead of failing the generation.
+	ResolveNameConflicts bool
+
 	// PrepareStructFields is called before adding struct field getters.
 	PrepareStructFields func(s *Struct)
 
 	// FilterStructFieldGetter determines if a getter should be generated for a field.
 	FilterStructFieldGetter func(f *StructField) bool
 
+	// CopyCArrays copies C arrays which are returned as slices into Go memory instead of referencing the C memory, so
+	// the slices stay valid after the C memory is disposed.
+	CopyCArrays bool
+
+	// NullHandling selects how structs which wrap a C pointer expose whether the pointer is NULL.
+	NullHandling NullHandling
+
+	// FilterNullableFunction determines if the result of a function is returned together with whether it is not NULL
+	// if NullHandling is NullHandlingOK. Excluded functions keep their single result, e.g. since hand-written code
+	// calls them.
+	FilterNullableFunction func(f *Function) bool
+
+	// CgoCheck selects how functions which would pass Go memory containing Go pointers to C are handled.
+	CgoCheck CgoCheck
+
 	// ClangArguments holds the command line arguments for Clang.
 	ClangArguments []string
+
+	// CompilationDatabase holds the build directory of a compile_commands.json. If it is set, every header file is
+	// parsed with the arguments of its own command or, if it has none, of the first command of the database and the
+	// language of its source file, in addition to ClangArguments.
+	CompilationDatabase string
+
+	// CompilationDatabaseHeaderDir holds the directory of the original header files if HandleDirectory handles copies
+	// of them. The commands of CompilationDatabase are looked up by the paths of the original header files then.
+	CompilationDatabaseHeaderDir string
+
+	// LLVMVersion holds the version of the LLVM whose headers are used for the generation, e.g. "14.0.0".
+	LLVMVersion string
+
+	// Backend selects how the generated bindings call into libclang.
+	Backend Backend
+
+	// Output receives the generated files. The files are written into the "clang" directory of the working directory if
+	// it is nil.
+	Output Output
+
+	// UmbrellaHeader holds the C header which is included by the cgo preamble of every generated file after the headers
+	// of its symbols. DefaultUmbrellaHeader is used if it is empty. The hand-written files which the go-clang-gen
+	// command adds to the bindings are not generated and still include DefaultUmbrellaHeader.
+	UmbrellaHeader string
+
+	// CFlags holds additional cgo CFLAGS of the generated bindings, e.g. "-I/opt/llvm/include".
+	CFlags []string
+
+	// LDFlags holds cgo LDFLAGS of the generated bindings, e.g. "-lclang-15". They are not used by builds with the
+	// "static" build tag. If LDFlags or PkgConfig are set, the default "-lclang" of cgoflags_dynamic.go should not be
+	// used.
+	LDFlags []string
+
+	// PkgConfig holds pkg-config package names whose flags are used by the generated bindings.
+	PkgConfig []string
+
+	// FileTemplate is the template of the generated Go files, see NewFileTemplate. DefaultFileTemplate is used if it is
+	// nil.
+	FileTemplate *template.Template
+
+	// HeaderTemplate is the template of the header of every generated file, see NewHeaderTemplate.
+	// DefaultHeaderTemplate is used if it is nil.
+	HeaderTemplate *template.Template
+
+	// AvailabilityTrees holds clang-c header directories of older LLVM versions, ordered from the oldest to the newest,
+	// which are used to annotate in which version a symbol was introduced.
+	AvailabilityTrees []AvailabilityTree
 }
 
-// HandleDirectory handles header files on dir and returns the *HeaderFile slice.
-func (a *API) HandleDirectory(dir string) ([]*HeaderFile, error) {
+// Backend defines how the generated bindings call into libclang.
+type Backend int
+
+const (
+	// BackendCgo calls libclang through cgo and links it at build time.
+	BackendCgo Backend = iota
+	// BackendDlopen additionally generates bindings for builds without cgo which load libclang at runtime via dlopen.
+	// These bindings only cover functions whose parameters and results are primitives, enums, strings and opaque
+	// pointer handles like CXIndex. Functions taking or returning structs by value, e.g. CXCursor, CXType and CXString,
+	// or slices, out-parameters and callbacks are left out and added to the report.
+	BackendDlopen
+)
+
+// HandleDirectory handles header files on dir and returns the *HeaderFile slice. It returns early if ctx is canceled.
+func (a *API) HandleDirectory(ctx context.Context, dir string) ([]*HeaderFile, error) {
 	headers, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read clang-c directory: %w", err)
 	}
 
+	var db *compilationDatabase
+	if a.CompilationDatabase != "" {
+		if db, err = openCompilationDatabase(a.CompilationDatabase); err != nil {
+			return nil, err
+		}
+		defer db.Dispose()
+	}
+
 	headerFiles := make([]*HeaderFile, 0, len(headers))
 	for _, hf := range headers {
 		if hf.IsDir() || !strings.HasSuffix(hf.Name(), ".h") {
 			continue
 		}
 
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		h := NewHeaderFile(a, hf.Name(), dir)
 
-		if err := h.Parse(a.ClangArguments); err != nil {
+		clangArguments := a.ClangArguments
+		if db != nil {
+			path := h.FullPath()
+			if a.CompilationDatabaseHeaderDir != "" {
+				path = filepath.Join(a.CompilationDatabaseHeaderDir, hf.Name())
+			}
+
+			args, err := db.Arguments(path)
+			if err != nil {
+				return nil, fmt.Errorf("cannot determine compile command of %q: %w", path, err)
+			}
+
+			clangArguments = append(append([]string{}, args...), a.ClangArguments...)
+		}
+
+		if err := h.Parse(ctx, clangArguments); err != nil {
 			return nil, fmt.Errorf("cannot handle header file %q: %w", h.FullPath(), err)
 		}
 
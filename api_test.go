@@ -1,6 +1,7 @@
 package gen_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -1144,6 +1145,7 @@ func TestAPI_HandleDirectory(t *testing.T) {
 							Name:         "bar",
 							CName:        "bar",
 							Parameters:   []gen.FunctionParameter{},
+							Location:     "testdata/api/bar.h:5:6",
 							ReturnType: gen.Type{
 								CName:         "void",
 								CGoName:       "void",
@@ -1167,6 +1169,7 @@ func TestAPI_HandleDirectory(t *testing.T) {
 							Name:         "foo",
 							CName:        "foo",
 							Parameters:   []gen.FunctionParameter{},
+							Location:     "testdata/api/foo.h:5:6",
 							ReturnType: gen.Type{
 								CName:         "void",
 								CGoName:       "void",
@@ -1192,7 +1195,7 @@ func TestAPI_HandleDirectory(t *testing.T) {
 			a := &gen.API{
 				ClangArguments: tt.ClangArguments,
 			}
-			got, err := a.HandleDirectory(tt.dir)
+			got, err := a.HandleDirectory(context.Background(), tt.dir)
 			if err != nil {
 				t.Fatalf("API.HandleDirectory(%v) error = %v", tt.dir, err)
 			}
@@ -40,16 +40,16 @@ func NewASTFunc(f *Function) *ASTFunc {
 }
 
 // GenerateFunctionString generates function string.
-func GenerateFunctionString(af *ASTFunc) string {
+func GenerateFunctionString(af *ASTFunc) (string, error) {
 	var b strings.Builder
 	if err := format.Node(&b, token.NewFileSet(), []ast.Decl{af.FuncDecl}); err != nil {
-		panic(fmt.Errorf("unexpected error: %w", err))
+		return "", fmt.Errorf("cannot format function %s: %w", af.Name.Name, err)
 	}
 
 	fnName := b.String()
 	fnName = strings.ReplaceAll(fnName, fmt.Sprintf("%s()", fakeStatement), "")
 
-	return fnName
+	return fnName, nil
 }
 
 // Generate generates function.
@@ -76,7 +76,7 @@ func (af *ASTFunc) Generate() {
 	if af.f.Member != nil {
 		if af.f.ReturnType.IsSlice {
 			af.AddStatement(doDeclare("s", doGoType(af.f.ReturnType)))
-			af.AddCToGoSliceConversion("s", af.f.Receiver.Name+".c."+af.f.Member.Name, af.f.Receiver.Name+".c."+af.f.ReturnType.LengthOfSlice)
+			af.AddCToGoSliceConversion("s", doGoType(af.f.ReturnType).(*ast.ArrayType).Elt, af.f.Receiver.Name+".c."+af.f.Member.Name, af.f.Receiver.Name+".c."+af.f.ReturnType.LengthOfSlice, af.f.ReturnType.IsCopiedSlice)
 
 			af.AddReturnItem(&ast.Ident{
 				Name: "s",
@@ -94,9 +94,16 @@ func (af *ASTFunc) Generate() {
 				},
 			})
 		}
+	} else if af.f.Variable != nil {
+		// read the global variable
+		af.GenerateReturn(accessMember("C", af.f.Variable.CName))
 	} else {
-		// basic call to the C function
-		call := doCCast(af.f.CName)
+		// basic call to the C function, or its shim if it cannot be called directly
+		cname := af.f.CName
+		if af.f.Shim != "" {
+			cname = af.f.Shim
+		}
+		call := doCCast(cname)
 
 		if callArguments := af.GenerateParameters(); len(callArguments) > 0 {
 			call.Args = callArguments
@@ -159,6 +166,8 @@ func (af *ASTFunc) GenerateParameters() []ast.Expr {
 
 			if p.Type.CGoName == CSChar && p.Type.PointerLevel >= 1 { // one pointer level from being a string, one from being an array
 				af.AddGoToCSliceConversion(p.Name, p.Type)
+			} else if p.Type.IsCopiedToC {
+				af.AddCArrayCopyFromGoSlice(p.Name, p.Type)
 			} else {
 				af.AddCArrayFromGoSlice(p.Name, p.Type)
 			}
@@ -182,19 +191,7 @@ func (af *ASTFunc) GenerateParameters() []ast.Expr {
 			}
 
 			// declare the return argument's variable
-			var varType ast.Expr
-			switch {
-			case p.Type.PointerLevel > 0 && p.Type.CGoName == CSChar:
-				varType = doPointer(doCType("char"))
-
-			case p.Type.IsPrimitive:
-				varType = doCType(p.Type.CGoName)
-
-			default:
-				varType = &ast.Ident{
-					Name: p.Type.GoName,
-				}
-			}
+			varType := returnArgumentType(p.Type)
 			if p.Type.IsSlice {
 				varType = &ast.ArrayType{
 					Elt: varType,
@@ -208,19 +205,14 @@ func (af *ASTFunc) GenerateParameters() []ast.Expr {
 
 			switch {
 			case p.Type.GoName == "cxstring":
-				af.AddDefer(doCall(p.Name, "Dispose"))
+				if p.Type.Ownership != OwnershipBorrowed {
+					af.AddDefer(doCall(p.Name, "Dispose"))
+				}
 
 			case p.Type.PointerLevel > 0 && p.Type.CGoName == CSChar:
-				af.AddDefer(doCCast(
-					"free",
-					doCall(
-						"unsafe",
-						"Pointer",
-						&ast.Ident{
-							Name: p.Name,
-						},
-					),
-				))
+				if release := releaseCMemory(p.Name, p.Type, OwnershipCallerOwned); release != nil {
+					af.AddDefer(release)
+				}
 			}
 
 			if p.Type.LengthOfSlice == "" {
@@ -415,7 +407,9 @@ func (af *ASTFunc) GenerateReturn(call ast.Expr) {
 		if returnType.GoName == "cxstring" {
 			// do the C function call and save the result into the new variable "o" while transforming it into a cxstring
 			af.AddAssignment("o", doCompose("cxstring", call))
-			af.AddDefer(doCall("o", "Dispose"))
+			if returnType.Ownership != OwnershipBorrowed {
+				af.AddDefer(doCall("o", "Dispose"))
+			}
 			af.AddEmptyLine()
 
 			// call the String method on the cxstring instance
@@ -453,10 +447,27 @@ func (af *ASTFunc) GenerateReturn(call ast.Expr) {
 			// TODO(go-clang): refactor the const char * check so that one function is used everywhere to check for that C type
 			// https://github.com/go-clang/gen/issues/56
 			case returnType.CGoName == CSChar && returnType.PointerLevel == 1:
-				// if this is a normal const char * C type there is not so much to do
+				release := releaseCMemory("o", returnType, OwnershipBorrowed)
+				if release == nil {
+					// if this is a normal const char * C type there is not so much to do
+					af.AddReturnItem(doCCast(
+						"GoString",
+						call,
+					))
+
+					break
+				}
+
+				// the string is owned by the caller so it has to be released after it has been copied
+				af.AddAssignment("o", call)
+				af.AddDefer(release)
+				af.AddEmptyLine()
+
 				af.AddReturnItem(doCCast(
 					"GoString",
-					call,
+					&ast.Ident{
+						Name: "o",
+					},
 				))
 
 			case returnType.GoName == "time.Time":
@@ -528,6 +539,22 @@ func (af *ASTFunc) GenerateReturn(call ast.Expr) {
 					Name: "gop_o",
 				})
 
+				if returnType.IsNullable {
+					// return whether the C pointer is not NULL
+					af.AddReturnItem(&ast.BinaryExpr{
+						X: &ast.Ident{
+							Name: "gop_o",
+						},
+						Op: token.NEQ,
+						Y: &ast.Ident{
+							Name: "nil",
+						},
+					})
+					af.AddReturnType("", Type{
+						GoName: GoBool,
+					})
+				}
+
 			default:
 				var convCall ast.Expr
 
@@ -538,7 +565,26 @@ func (af *ASTFunc) GenerateReturn(call ast.Expr) {
 					convCall = doCompose(returnType.GoName, call)
 				}
 
-				if len(af.ret.Results) > 0 {
+				if returnType.IsNullable {
+					// do the C function call and save the result into the new variable "o"
+					af.AddAssignment("o", convCall)
+					af.AddEmptyLine()
+
+					// return the C function call result and whether its C pointer is not NULL
+					af.AddReturnItem(&ast.Ident{
+						Name: "o",
+					})
+					af.AddReturnItem(&ast.BinaryExpr{
+						X:  accessMember("o", "c"),
+						Op: token.NEQ,
+						Y: &ast.Ident{
+							Name: "nil",
+						},
+					})
+					af.AddReturnType("", Type{
+						GoName: GoBool,
+					})
+				} else if len(af.ret.Results) > 0 {
 					// do the C function call and save the result into the new variable "o"
 					af.AddAssignment("o", convCall)
 					af.AddEmptyLine()
@@ -634,7 +680,7 @@ func (af *ASTFunc) AddCToGoConversions() {
 				}
 			}
 
-			af.AddCToGoSliceConversion(p.Name, "cp_"+p.Name, lengthOfSlice)
+			af.AddCToGoSliceConversion(p.Name, returnArgumentType(p.Type), "cp_"+p.Name, lengthOfSlice, p.Type.IsCopiedSlice)
 		}
 	}
 
@@ -644,68 +690,66 @@ func (af *ASTFunc) AddCToGoConversions() {
 }
 
 // AddCToGoSliceConversion adds C to Go slice conversion to af.
-func (af *ASTFunc) AddCToGoSliceConversion(name string, cname string, lengthOfSlice string) {
-	af.AddAssignment("gos_"+name, &ast.CallExpr{
-		Fun: &ast.ParenExpr{
-			X: doPointer(accessMember("reflect", "SliceHeader")),
-		},
-		Args: []ast.Expr{
-			doCall(
-				"unsafe",
-				"Pointer",
-				doReference(&ast.Ident{
-					Name: name,
-				}),
-			),
+//
+// The slice name with elements of type elem references the C array cname of length lengthOfSlice. If copy is set, the
+// elements are copied into Go memory instead so the slice stays valid after the C array is disposed.
+func (af *ASTFunc) AddCToGoSliceConversion(name string, elem ast.Expr, cname string, lengthOfSlice string, copyElements bool) {
+	var slice ast.Expr = doCall(
+		"unsafe",
+		"Slice",
+		&ast.CallExpr{
+			Fun: &ast.ParenExpr{
+				X: doPointer(elem),
+			},
+			Args: []ast.Expr{
+				doCall(
+					"unsafe",
+					"Pointer",
+					&ast.Ident{
+						Name: cname,
+					},
+				),
+			},
 		},
-	})
+		doCast(
+			"int",
+			&ast.Ident{
+				Name: lengthOfSlice,
+			},
+		),
+	)
 
-	af.AddStatement(&ast.AssignStmt{
-		Lhs: []ast.Expr{
-			accessMember("gos_"+name, "Cap"),
-		},
-		Tok: token.ASSIGN,
-		Rhs: []ast.Expr{
-			doCast(
-				"int",
-				&ast.Ident{
-					Name: lengthOfSlice,
+	if copyElements {
+		slice = &ast.CallExpr{
+			Fun: &ast.Ident{
+				Name: "append",
+			},
+			Args: []ast.Expr{
+				&ast.CallExpr{
+					Fun: &ast.ArrayType{
+						Elt: elem,
+					},
+					Args: []ast.Expr{
+						&ast.Ident{
+							Name: "nil",
+						},
+					},
 				},
-			),
-		},
-	})
+				slice,
+			},
+			Ellipsis: 1,
+		}
+	}
 
 	af.AddStatement(&ast.AssignStmt{
 		Lhs: []ast.Expr{
-			accessMember("gos_"+name, "Len"),
+			&ast.Ident{
+				Name: name,
+			},
 		},
 		Tok: token.ASSIGN,
 		Rhs: []ast.Expr{
-			doCast(
-				"int",
-				&ast.Ident{
-					Name: lengthOfSlice,
-				},
-			),
-		},
-	})
-
-	af.AddStatement(&ast.AssignStmt{
-		Lhs: []ast.Expr{
-			accessMember("gos_"+name, "Data"),
-		},
-		Tok: token.ASSIGN,
-		Rhs: []ast.Expr{
-			doCast(
-				"uintptr",
-				doCall(
-					"unsafe",
-					"Pointer",
-					&ast.Ident{
-						Name: cname,
-					},
-				),
-			),
+			slice,
 		},
 	})
 }
@@ -714,31 +758,153 @@ func (af *ASTFunc) AddCToGoSliceConversion(name string, cname string, lengthOfSl
 func (af *ASTFunc) AddCArrayFromGoSlice(name string, typ Type) {
 	sliceType := sliceType(typ)
 
-	af.AddAssignment("gos_"+name, &ast.CallExpr{
+	af.AddAssignment("cp_"+name, &ast.CallExpr{
 		Fun: &ast.ParenExpr{
-			X: doPointer(accessMember("reflect", "SliceHeader")),
+			X: doPointer(sliceType),
 		},
 		Args: []ast.Expr{
 			doCall(
 				"unsafe",
 				"Pointer",
-				doReference(&ast.Ident{
-					Name: name,
-				}),
+				doCall(
+					"unsafe",
+					"SliceData",
+					&ast.Ident{
+						Name: name,
+					},
+				),
 			),
 		},
 	})
+}
 
-	af.AddAssignment("cp_"+name, &ast.CallExpr{
-		Fun: &ast.ParenExpr{
-			X: doPointer(sliceType),
+// AddCArrayCopyFromGoSlice adds a C allocated array holding the C values of the Go slice to af.
+//
+// The array is freed when the function returns. It is used for slices whose backing array must not be passed to C since
+// it contains Go pointers.
+func (af *ASTFunc) AddCArrayCopyFromGoSlice(name string, typ Type) {
+	sliceType := sliceType(typ)
+
+	af.AddStatement(doDeclare(
+		"cp_"+name,
+		doPointer(sliceType),
+	))
+
+	lenName := doCast(
+		"len",
+		&ast.Ident{
+			Name: name,
 		},
-		Args: []ast.Expr{
-			doCall(
-				"unsafe",
-				"Pointer",
-				accessMember("gos_"+name, "Data"),
-			),
+	)
+
+	af.AddStatement(&ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X:  lenName,
+			Op: token.GTR,
+			Y:  doZero(),
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{
+						&ast.Ident{
+							Name: "cp_" + name,
+						},
+					},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{
+						&ast.CallExpr{
+							Fun: &ast.ParenExpr{
+								X: doPointer(sliceType),
+							},
+							Args: []ast.Expr{
+								doCCast(
+									"calloc",
+									doCCast("size_t", lenName),
+									doCCast("size_t", doCall(
+										"unsafe",
+										"Sizeof",
+										doPointer(&ast.Ident{
+											Name: "cp_" + name,
+										}),
+									)),
+								),
+							},
+						},
+					},
+				},
+				&ast.DeferStmt{
+					Call: doCCast(
+						"free",
+						doCall(
+							"unsafe",
+							"Pointer",
+							&ast.Ident{
+								Name: "cp_" + name,
+							},
+						),
+					),
+				},
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{
+						&ast.Ident{
+							Name: "ca_" + name,
+						},
+					},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{
+						doCall(
+							"unsafe",
+							"Slice",
+							&ast.Ident{
+								Name: "cp_" + name,
+							},
+							lenName,
+						),
+					},
+				},
+				&ast.RangeStmt{
+					Key: &ast.Ident{
+						Name: "i",
+					},
+					Tok: token.DEFINE,
+					X: &ast.Ident{
+						Name: name,
+					},
+					Body: &ast.BlockStmt{
+						List: []ast.Stmt{
+							&ast.AssignStmt{
+								Lhs: []ast.Expr{
+									&ast.IndexExpr{
+										X: &ast.Ident{
+											Name: "ca_" + name,
+										},
+										Index: &ast.Ident{
+											Name: "i",
+										},
+									},
+								},
+								Tok: token.ASSIGN,
+								Rhs: []ast.Expr{
+									&ast.SelectorExpr{
+										X: &ast.IndexExpr{
+											X: &ast.Ident{
+												Name: name,
+											},
+											Index: &ast.Ident{
+												Name: "i",
+											},
+										},
+										Sel: &ast.Ident{
+											Name: "c",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 		},
 	})
 }
@@ -905,3 +1071,45 @@ func (af *ASTFunc) AddGoToCSliceConversion(name string, typ Type) {
 		},
 	})
 }
+
+// resolveOwnership returns the ownership of the C memory referenced by typ. An unknown ownership of memory which is only
+// readable through typ is borrowed, any other unknown ownership is def.
+func resolveOwnership(typ Type, def Ownership) Ownership {
+	switch {
+	case typ.Ownership != OwnershipUnknown:
+		return typ.Ownership
+
+	case typ.IsConstAt(typ.PointerLevel):
+		return OwnershipBorrowed
+	}
+
+	return def
+}
+
+// releaseCMemory returns the call which releases the C memory of the variable name with the type typ, or nil if the
+// memory is borrowed. def is used if the ownership of typ is unknown.
+func releaseCMemory(name string, typ Type, def Ownership) *ast.CallExpr {
+	switch resolveOwnership(typ, def) {
+	case OwnershipCallerOwned:
+		return doCCast(
+			"free",
+			doCall(
+				"unsafe",
+				"Pointer",
+				&ast.Ident{
+					Name: name,
+				},
+			),
+		)
+
+	case OwnershipDisposedBy:
+		return doCCast(
+			typ.DisposedBy,
+			&ast.Ident{
+				Name: name,
+			},
+		)
+	}
+
+	return nil
+}
@@ -173,6 +173,22 @@ func doStringLit(value string) *ast.BasicLit {
 	}
 }
 
+// returnArgumentType returns the type of the variable which receives the return argument typ.
+func returnArgumentType(typ Type) ast.Expr {
+	switch {
+	case typ.PointerLevel > 0 && typ.CGoName == CSChar:
+		return doPointer(doCType("char"))
+
+	case typ.IsPrimitive:
+		return doCType(typ.CGoName)
+
+	default:
+		return &ast.Ident{
+			Name: typ.GoName,
+		}
+	}
+}
+
 func sliceType(typ Type) ast.Expr {
 	var sliceType ast.Expr
 
@@ -0,0 +1,151 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AvailabilityTree represents a clang-c header directory of a specific LLVM version.
+type AvailabilityTree struct {
+	// Version is the LLVM version of the headers, e.g. "9".
+	Version string
+	// Dir is the path of the clang-c header directory.
+	Dir string
+}
+
+// availabilitySymbols holds the symbols of an AvailabilityTree.
+type availabilitySymbols map[string]struct{}
+
+// structFieldSymbol returns the availability symbol of a struct field.
+func structFieldSymbol(s *Struct, f *StructField) string {
+	return s.CName + "." + f.CName
+}
+
+// collectAvailabilitySymbols parses the headers of t and returns their functions, enum items and struct fields.
+//
+// In contrast to API.HandleDirectory the header files are not modified.
+func collectAvailabilitySymbols(ctx context.Context, a *API, t AvailabilityTree) (availabilitySymbols, error) {
+	headers, err := os.ReadDir(t.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read clang-c directory: %w", err)
+	}
+
+	// the headers include each other relative to their parent directory
+	clangArguments := append([]string{"-I" + filepath.Dir(filepath.Clean(t.Dir))}, a.ClangArguments...)
+
+	symbols := availabilitySymbols{}
+	for _, hf := range headers {
+		if hf.IsDir() || !strings.HasSuffix(hf.Name(), ".h") {
+			continue
+		}
+
+		h := NewHeaderFile(a, hf.Name(), t.Dir)
+
+		if err := h.parse(ctx, clangArguments); err != nil {
+			return nil, fmt.Errorf("cannot handle header file %q: %w", h.FullPath(), err)
+		}
+
+		for _, f := range h.Functions {
+			symbols[f.CName] = struct{}{}
+		}
+
+		for _, e := range h.Enums {
+			for _, ei := range e.Items {
+				symbols[ei.CName] = struct{}{}
+			}
+		}
+
+		for _, s := range h.Structs {
+			for _, f := range s.Fields {
+				symbols[structFieldSymbol(s, f)] = struct{}{}
+			}
+		}
+	}
+
+	return symbols, nil
+}
+
+// ComputeAvailability computes for every function, enum item and struct field the first LLVM version of the
+// API.AvailabilityTrees which introduced it, and adds it to its comment and the report.
+//
+// The trees must be ordered from the oldest to the newest version. Symbols which are already part of the oldest tree
+// are not annotated. Symbols which are not part of any tree are annotated with API.LLVMVersion, the version the
+// bindings are generated for.
+func (g *Generation) ComputeAvailability(ctx context.Context) error {
+	trees := make([]availabilitySymbols, len(g.api.AvailabilityTrees))
+	for i, t := range g.api.AvailabilityTrees {
+		symbols, err := collectAvailabilitySymbols(ctx, g.api, t)
+		if err != nil {
+			return fmt.Errorf("cannot collect symbols of LLVM %s: %w", t.Version, err)
+		}
+
+		trees[i] = symbols
+	}
+
+	since := func(symbol string) string {
+		return g.availabilitySince(trees, symbol)
+	}
+
+	for _, f := range g.functions {
+		f.Since = since(f.CName)
+		f.Comment = AvailabilityComment(f.Comment, f.Since)
+	}
+
+	for _, e := range g.enums {
+		for i := range e.Items {
+			ei := &e.Items[i]
+
+			ei.Since = since(ei.CName)
+			ei.Comment = AvailabilityComment(ei.Comment, ei.Since)
+		}
+	}
+
+	for _, s := range g.structs {
+		for _, f := range s.Fields {
+			f.Since = since(structFieldSymbol(s, f))
+			f.Comment = AvailabilityComment(f.Comment, f.Since)
+		}
+	}
+
+	return nil
+}
+
+// availabilitySince returns the LLVM version which introduced symbol according to trees, which hold the symbols of
+// API.AvailabilityTrees, and adds it to the report. It returns an empty string for symbols of the oldest tree.
+func (g *Generation) availabilitySince(trees []availabilitySymbols, symbol string) string {
+	v := g.api.LLVMVersion
+	for i, symbols := range trees {
+		if _, ok := symbols[symbol]; ok {
+			if i == 0 {
+				return ""
+			}
+
+			v = g.api.AvailabilityTrees[i].Version
+
+			break
+		}
+	}
+
+	if v != "" {
+		g.report.Add(ReportAvailability, symbol, "available since LLVM %s", v)
+	}
+
+	return v
+}
+
+// AvailabilityComment appends the availability of since to the Go comment.
+func AvailabilityComment(comment string, since string) string {
+	if since == "" {
+		return comment
+	}
+
+	note := "// Available since LLVM " + since + "."
+	if comment == "" {
+		return note
+	}
+
+	return comment + "\n//\n" + note
+}
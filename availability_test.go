@@ -0,0 +1,120 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestAvailabilityComment(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		comment string
+		since   string
+		want    string
+	}{
+		"unknown": {
+			comment: `// Foo does something.`,
+			since:   "",
+			want:    `// Foo does something.`,
+		},
+		"empty comment": {
+			comment: ``,
+			since:   "9",
+			want:    `// Available since LLVM 9.`,
+		},
+		"comment": {
+			comment: `// Foo does something.`,
+			since:   "9",
+			want: `// Foo does something.
+//
+// Available since LLVM 9.`,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := gen.AvailabilityComment(tt.comment, tt.since); got != tt.want {
+				t.Fatalf("AvailabilityComment(%q, %q) = %q, want %q", tt.comment, tt.since, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneration_AvailabilitySince(t *testing.T) {
+	t.Parallel()
+
+	trees := []gen.AvailabilitySymbols{
+		{"clang_getCString": {}},
+		{"clang_getCString": {}, "clang_Cursor_isNull": {}},
+		{"clang_getCString": {}, "clang_Cursor_isNull": {}, "clang_File_tryGetRealPathName": {}},
+	}
+
+	tests := map[string]struct {
+		llvmVersion string
+		symbol      string
+		want        string
+		wantReport  []gen.ReportEntry
+	}{
+		"oldest tree": {
+			llvmVersion: "15.0.7",
+			symbol:      "clang_getCString",
+			want:        "",
+		},
+		"first tree containing the symbol": {
+			llvmVersion: "15.0.7",
+			symbol:      "clang_Cursor_isNull",
+			want:        "8",
+			wantReport: []gen.ReportEntry{
+				{Kind: gen.ReportAvailability, Symbol: "clang_Cursor_isNull", Message: "available since LLVM 8"},
+			},
+		},
+		"newest tree": {
+			llvmVersion: "15.0.7",
+			symbol:      "clang_File_tryGetRealPathName",
+			want:        "9",
+			wantReport: []gen.ReportEntry{
+				{Kind: gen.ReportAvailability, Symbol: "clang_File_tryGetRealPathName", Message: "available since LLVM 9"},
+			},
+		},
+		"no tree": {
+			llvmVersion: "15.0.7",
+			symbol:      "clang_getUnqualifiedType",
+			want:        "15.0.7",
+			wantReport: []gen.ReportEntry{
+				{Kind: gen.ReportAvailability, Symbol: "clang_getUnqualifiedType", Message: "available since LLVM 15.0.7"},
+			},
+		},
+		"no tree without LLVM version": {
+			symbol: "clang_getUnqualifiedType",
+			want:   "",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g := gen.NewGeneration(&gen.API{
+				LLVMVersion: tt.llvmVersion,
+				AvailabilityTrees: []gen.AvailabilityTree{
+					{Version: "7", Dir: "7/clang-c"},
+					{Version: "8", Dir: "8/clang-c"},
+					{Version: "9", Dir: "9/clang-c"},
+				},
+			})
+
+			if got := g.AvailabilitySince(trees, tt.symbol); got != tt.want {
+				t.Fatalf("AvailabilitySince(%q) = %q, want %q", tt.symbol, got, tt.want)
+			}
+			if diff := cmp.Diff(tt.wantReport, g.Report().Entries); diff != "" {
+				t.Fatalf("AvailabilitySince(%q) report: (-want +got):\n%s", tt.symbol, diff)
+			}
+		})
+	}
+}
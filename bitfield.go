@@ -0,0 +1,103 @@
+package gen
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// bitFieldGetter holds the generation data of a getter which reads a bitfield through a shim.
+type bitFieldGetter struct {
+	Comment      string
+	Name         string
+	Receiver     string
+	ReceiverType string
+	Shim         string
+	// Argument holds the pointer to the C struct which is passed to the shim
+	Argument string
+	Type     string
+	IsBool   bool
+}
+
+var templateGenerateBitFieldGetter = template.Must(template.New("go-clang-generate-bitfield-getter").Parse(`{{if $.Comment}}{{$.Comment}}
+{{end}}func ({{$.Receiver}} {{$.ReceiverType}}) {{$.Name}}() {{$.Type}} {
+	return {{if $.IsBool}}C.{{$.Shim}}({{$.Argument}}) != 0{{else}}{{$.Type}}(C.{{$.Shim}}({{$.Argument}})){{end}}
+}`))
+
+// isBitFieldType reports whether a bitfield of the type typ can be read through a shim.
+func (g *Generation) isBitFieldType(typ Type) bool {
+	if typ.PointerLevel != 0 || typ.IsArray {
+		return false
+	}
+
+	if typ.GoName == GoBool || IsInteger(&typ) {
+		return true
+	}
+
+	_, ok := g.HasEnum(typ.GoName)
+
+	return ok
+}
+
+// AddBitFieldGetters adds getters to s for all its bitfields. Since cgo cannot address bitfields, every getter reads
+// its bitfield through a generated C shim. Bitfields of unsupported types are added to the report.
+func (g *Generation) AddBitFieldGetters(s *Struct) error {
+	ctype := s.CName
+	if !s.CNameIsTypeDef {
+		ctype = "struct " + ctype
+	}
+
+	receiverType := s.Name
+	argument := "&" + s.Receiver.Name + ".c"
+	if s.IsPointerComposition {
+		receiverType = "*" + receiverType
+		argument = s.Receiver.Name + ".c"
+	}
+
+	for _, m := range s.Fields {
+		if m.BitWidth == 0 {
+			continue
+		}
+		if g.api.FilterStructFieldGetter != nil && !g.api.FilterStructFieldGetter(m) {
+			continue
+		}
+
+		if !g.isBitFieldType(m.Type) {
+			g.report.Add(ReportSkipped, s.CName+"."+m.CName, "bitfield of type %q is not supported", m.Type.CName)
+
+			continue
+		}
+
+		name := UpperFirstCharacter(m.CName)
+		if s.ContainsMethod(name) {
+			continue
+		}
+
+		shim := &Shim{
+			IncludeFiles: s.IncludeFiles,
+			Name:         shimPrefix + s.CName + "_" + m.CName,
+			CName:        m.CName,
+			Declaration:  cDeclaration(m.Type.CName, shimPrefix+s.CName+"_"+m.CName) + "(const " + ctype + " *s)",
+			Call:         "return s->" + m.CName + ";",
+		}
+		g.shims = append(g.shims, shim)
+
+		var b bytes.Buffer
+		if err := templateGenerateBitFieldGetter.Execute(&b, bitFieldGetter{
+			Comment:      m.Comment,
+			Name:         name,
+			Receiver:     s.Receiver.Name,
+			ReceiverType: receiverType,
+			Shim:         shim.Name,
+			Argument:     argument,
+			Type:         m.Type.GoName,
+			IsBool:       m.Type.GoName == GoBool,
+		}); err != nil {
+			return err
+		}
+
+		s.Methods = append(s.Methods, b.String())
+		s.IncludeFiles.AddIncludeFile("./clang/" + shimsFilename + ".h")
+	}
+
+	return nil
+}
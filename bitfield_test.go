@@ -0,0 +1,46 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestAddBitFieldGetters(t *testing.T) {
+	t.Parallel()
+
+	g := gen.NewGeneration(&gen.API{})
+
+	s := &gen.Struct{
+		IncludeFiles: gen.NewIncludeFiles(),
+		Name:         "Flags",
+		CName:        "Flags",
+		Receiver:     gen.Receiver{Name: "f"},
+		Fields: []*gen.StructField{
+			{CName: "count", Type: gen.Type{CName: "int", GoName: gen.GoInt32}},
+			{CName: "isPacked", BitWidth: 1, Type: gen.Type{CName: "unsigned int", GoName: gen.GoUInt32}},
+			{CName: "hasFlag", BitWidth: 1, Type: gen.Type{CName: "_Bool", GoName: gen.GoBool}},
+			{CName: "ratio", BitWidth: 4, Type: gen.Type{CName: "double", GoName: gen.GoFloat64}},
+		},
+	}
+	if err := g.AddBitFieldGetters(s); err != nil {
+		t.Fatalf("AddBitFieldGetters() error = %v", err)
+	}
+
+	want := []interface{}{
+		"func (f Flags) IsPacked() uint32 {\n\treturn uint32(C.go_clang_shim_Flags_isPacked(&f.c))\n}",
+		"func (f Flags) HasFlag() bool {\n\treturn C.go_clang_shim_Flags_hasFlag(&f.c) != 0\n}",
+	}
+	if diff := cmp.Diff(want, s.Methods); diff != "" {
+		t.Fatalf("Struct.Methods: (-want +got):\n%s", diff)
+	}
+
+	wantReport := []gen.ReportEntry{
+		{Kind: gen.ReportSkipped, Symbol: "Flags.ratio", Message: `bitfield of type "double" is not supported`},
+	}
+	if diff := cmp.Diff(wantReport, g.Report().Entries); diff != "" {
+		t.Fatalf("Report().Entries: (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,527 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"text/template"
+)
+
+// callbackTable holds the generation data of a struct whose fields are callbacks which receive client data.
+type callbackTable struct {
+	Name  string
+	CName string
+	// CType holds the type of the table in C code.
+	CType   string
+	Handler string
+	// Registry holds the name of the variable which maps client data to registrations.
+	Registry string
+	// Registration holds the name of the type which holds a registered handler and the panic of its callbacks.
+	Registration string
+	Callbacks    []callbackFunction
+	// UmbrellaHeader holds the C header which declares the callback table, see API.UmbrellaHeader.
+	UmbrellaHeader string
+}
+
+// callbackFunction holds the generation data of a single callback of a callbackTable.
+type callbackFunction struct {
+	Name    string
+	CName   string
+	Comment string
+	Export  string
+
+	// GoParameters and GoResult define the method of the handler interface.
+	GoParameters string
+	GoResult     string
+	// Zero holds the zero value of GoResult.
+	Zero string
+
+	// ExportParameters and ExportResult define the exported Go function which is called by C.
+	ExportParameters string
+	ExportResult     string
+	// Call holds the call of the handler method including the conversion of its result.
+	Call string
+}
+
+// callbackConversion describes how a C value is passed between a C callback and the Go handler.
+type callbackConversion struct {
+	// GoType is the type used by the handler, it is empty if the value is not passed to the handler.
+	GoType string
+	// CType is the type used by the exported Go function.
+	CType string
+	// Convert converts a value as fmt pattern, from CType to GoType for parameters and from GoType to CType for results.
+	Convert string
+	// Zero holds the zero value of GoType.
+	Zero string
+}
+
+// structCType returns the cgo type of the struct s.
+func structCType(s *Struct) string {
+	if s.CNameIsTypeDef {
+		return "C." + s.CName
+	}
+
+	return "C.struct_" + s.CName
+}
+
+// callbackParameter returns how the parameter typ of a callback is passed to the handler.
+func (g *Generation) callbackParameter(typ Type) (callbackConversion, bool) {
+	switch {
+	case typ.IsArray || typ.IsFunctionPointer:
+		return callbackConversion{}, false
+
+	case typ.PointerLevel == 1 && typ.GoName == "void":
+		// reserved and opaque pointers are not passed to the handler
+		return callbackConversion{CType: "unsafe.Pointer"}, true
+
+	case typ.PointerLevel == 1 && typ.CGoName == CSChar:
+		return callbackConversion{GoType: "string", CType: "*C.char", Convert: "C.GoString(%s)"}, true
+	}
+
+	if s, ok := g.HasStruct(typ.GoName); ok {
+		switch {
+		case typ.PointerLevel == 0 && !s.IsPointerComposition:
+			return callbackConversion{GoType: s.Name, CType: structCType(s), Convert: s.Name + "{%s}"}, true
+
+		case typ.PointerLevel == 1 && s.IsPointerComposition:
+			return callbackConversion{GoType: "*" + s.Name, CType: "*" + structCType(s), Convert: "&" + s.Name + "{%s}"}, true
+
+		case typ.PointerLevel == 1:
+			return callbackConversion{GoType: "*" + s.Name, CType: "*" + structCType(s), Convert: "(*" + s.Name + ")(unsafe.Pointer(%s))"}, true
+		}
+
+		return callbackConversion{}, false
+	}
+
+	if typ.PointerLevel != 0 {
+		return callbackConversion{}, false
+	}
+
+	if e, ok := g.HasEnum(typ.GoName); ok {
+		return callbackConversion{GoType: e.Name, CType: "C." + e.Receiver.Type.CGoName, Convert: e.Name + "(%s)"}, true
+	}
+
+	if _, ok := dlopenCTypes[typ.CGoName]; ok && typ.GoName != GoBool {
+		return callbackConversion{GoType: typ.GoName, CType: "C." + typ.CGoName, Convert: typ.GoName + "(%s)"}, true
+	}
+
+	return callbackConversion{}, false
+}
+
+// callbackResult returns how the result typ of a handler is passed back to the C callback.
+func (g *Generation) callbackResult(typ Type) (callbackConversion, bool) {
+	if typ.PointerLevel == 0 && typ.GoName == "void" {
+		return callbackConversion{Convert: "%s"}, true
+	}
+
+	if typ.PointerLevel != 0 || typ.IsArray {
+		return callbackConversion{}, false
+	}
+
+	if s, ok := g.HasStruct(typ.GoName); ok {
+		if s.IsPointerComposition {
+			return callbackConversion{}, false
+		}
+
+		return callbackConversion{GoType: s.Name, CType: structCType(s), Convert: "%s.c", Zero: s.Name + "{}"}, true
+	}
+
+	if e, ok := g.HasEnum(typ.GoName); ok {
+		return callbackConversion{GoType: e.Name, CType: "C." + e.Receiver.Type.CGoName, Convert: "C." + e.Receiver.Type.CGoName + "(%s)", Zero: "0"}, true
+	}
+
+	if _, ok := dlopenCTypes[typ.CGoName]; ok && typ.GoName != GoBool {
+		return callbackConversion{GoType: typ.GoName, CType: "C." + typ.CGoName, Convert: "C." + typ.CGoName + "(%s)", Zero: "0"}, true
+	}
+
+	return callbackConversion{}, false
+}
+
+// IsCallbackTable reports whether s is a table of callbacks which receive client data as their first parameter, e.g.
+// IndexerCallbacks.
+func (g *Generation) IsCallbackTable(s *Struct) bool {
+	if len(s.Callbacks) == 0 || len(s.Fields) != 0 {
+		return false
+	}
+
+	for _, cb := range s.Callbacks {
+		if len(cb.Parameters) == 0 || cb.Parameters[0].Type.GoName != "ClientData" || cb.Parameters[0].Type.PointerLevel != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newCallbackTable returns the generation data of the callback table s.
+func (g *Generation) newCallbackTable(s *Struct) (*callbackTable, error) {
+	t := &callbackTable{
+		Name:           s.Name,
+		CName:          s.CName,
+		CType:          s.CName,
+		Handler:        s.Name + "Handler",
+		Registry:       LowerFirstCharacter(s.Name) + "Handlers",
+		Registration:   LowerFirstCharacter(s.Name) + "Registration",
+		UmbrellaHeader: g.umbrellaHeader(),
+	}
+	if !s.CNameIsTypeDef {
+		t.CType = "struct " + s.CName
+	}
+
+	for _, cb := range s.Callbacks {
+		f := callbackFunction{
+			Name:    cb.Name,
+			CName:   cb.CName,
+			Comment: strings.ReplaceAll(cb.Comment, "\n", "\n\t"),
+			Export:  "GoClang" + s.Name + cb.Name,
+		}
+
+		var goParameters, exportParameters, arguments []string
+		names := map[string]bool{}
+
+		for i, p := range cb.Parameters[1:] {
+			conv, ok := g.callbackParameter(p.Type)
+			if !ok {
+				return nil, fmt.Errorf("parameter %q of callback %q with type %q is not supported", p.CName, cb.CName, p.Type.CName)
+			}
+
+			name := p.Name
+			// clientData, reg and result are used by the exported function
+			if name == "" || names[name] || name == "clientData" || name == "reg" || name == "result" {
+				name = fmt.Sprintf("p%d", i+1)
+			}
+			names[name] = true
+
+			exportParameters = append(exportParameters, name+" "+conv.CType)
+			if conv.GoType != "" {
+				goParameters = append(goParameters, name+" "+conv.GoType)
+				arguments = append(arguments, fmt.Sprintf(conv.Convert, name))
+			}
+		}
+
+		result, ok := g.callbackResult(cb.ReturnType)
+		if !ok {
+			return nil, fmt.Errorf("result of callback %q with type %q is not supported", cb.CName, cb.ReturnType.CName)
+		}
+
+		f.GoParameters = strings.Join(goParameters, ", ")
+		f.GoResult = result.GoType
+		f.Zero = result.Zero
+		f.ExportParameters = strings.Join(append([]string{"clientData C.CXClientData"}, exportParameters...), ", ")
+		f.ExportResult = result.CType
+		f.Call = fmt.Sprintf(result.Convert, "reg.handler."+cb.Name+"("+strings.Join(arguments, ", ")+")")
+
+		t.Callbacks = append(t.Callbacks, f)
+	}
+
+	return t, nil
+}
+
+var templateGenerateCallbackGoFile = template.Must(template.New("go-clang-generate-callback-go-file").Parse(`package clang
+
+// #include "{{$.UmbrellaHeader}}"
+//
+// void go_clang_init_{{$.CName}}({{$.CType}} *callbacks);
+import "C"
+import (
+	"unsafe"
+)
+
+// {{$.Handler}} handles the callbacks of {{$.Name}}.
+//
+// A handler is passed to the WithHandler variants of the functions which take the {{$.Name}} table. Embed
+// Nop{{$.Handler}} to implement only some of the callbacks.
+type {{$.Handler}} interface {
+{{range $cb := $.Callbacks}}{{if $cb.Comment}}	{{$cb.Comment}}
+{{end}}	{{$cb.Name}}({{$cb.GoParameters}}){{if $cb.GoResult}} {{$cb.GoResult}}{{end}}
+{{end}}}
+
+// Nop{{$.Handler}} implements {{$.Handler}} with callbacks which do nothing and return zero values.
+type Nop{{$.Handler}} struct{}
+{{range $cb := $.Callbacks}}
+// {{$cb.Name}} implements {{$.Handler}}.
+func (Nop{{$.Handler}}) {{$cb.Name}}({{$cb.GoParameters}}){{if $cb.GoResult}} {{$cb.GoResult}}{{end}} {
+{{if $cb.GoResult}}	return {{$cb.Zero}}
+{{end}}}
+{{end}}
+// {{$.Registration}} holds a registered {{$.Handler}} and the first panic of its callbacks.
+type {{$.Registration}} struct {
+	handler  {{$.Handler}}
+	panicked interface{}
+}
+
+// recoverPanic stores a panic of a callback since it must not unwind through the C frames of libclang.
+func (reg *{{$.Registration}}) recoverPanic() {
+	if p := recover(); p != nil {
+		reg.panicked = p
+	}
+}
+
+var {{$.Registry}} = &funcRegistry[{{$.Registration}}]{
+	funcs: map[int]*{{$.Registration}}{},
+}
+
+// new{{$.Name}} returns a callback table and its client data which dispatch all callbacks to handler.
+//
+// release must be called after the callback table is not used anymore. If a callback panicked, the remaining callbacks
+// are not dispatched to handler anymore and release panics again with the same value.
+func new{{$.Name}}(handler {{$.Handler}}) (callbacks *{{$.Name}}, clientData ClientData, release func()) {
+	reg := &{{$.Registration}}{handler: handler}
+	i := {{$.Registry}}.register(reg)
+
+	// the index is allocated in C memory since the client data is kept by libclang during the whole indexing.
+	ci := (*C.int)(C.malloc(C.size_t(unsafe.Sizeof(C.int(0)))))
+	*ci = C.int(i)
+
+	callbacks = &{{$.Name}}{}
+	C.go_clang_init_{{$.CName}}(&callbacks.c)
+
+	return callbacks, ClientData{C.CXClientData(unsafe.Pointer(ci))}, func() {
+		{{$.Registry}}.unregister(i)
+		C.free(unsafe.Pointer(ci))
+
+		if reg.panicked != nil {
+			panic(reg.panicked)
+		}
+	}
+}
+
+func lookup{{$.Handler}}(clientData C.CXClientData) *{{$.Registration}} {
+	i := *(*C.int)(unsafe.Pointer(clientData))
+
+	return {{$.Registry}}.lookup(int(i))
+}
+{{range $cb := $.Callbacks}}
+// {{$cb.Export}} calls {{$.Handler}}.{{$cb.Name}}.
+//
+//export {{$cb.Export}}
+func {{$cb.Export}}({{$cb.ExportParameters}}){{if $cb.ExportResult}} (result {{$cb.ExportResult}}){{end}} {
+	reg := lookup{{$.Handler}}(clientData)
+	if reg.panicked != nil {
+		return
+	}
+	defer reg.recoverPanic()
+
+	{{if $cb.ExportResult}}return {{end}}{{$cb.Call}}
+}
+{{end}}`))
+
+var templateGenerateCallbackCFile = template.Must(template.New("go-clang-generate-callback-c-file").Parse(`#include "_cgo_export.h"
+#include "{{$.UmbrellaHeader}}"
+
+void go_clang_init_{{$.CName}}({{$.CType}} *callbacks) {
+{{range $cb := $.Callbacks}}	callbacks->{{$cb.CName}} = (__typeof__(callbacks->{{$cb.CName}}))&{{$cb.Export}};
+{{end}}}
+`))
+
+// Generate generates a Go handler interface for the callback table, exported Go functions which dispatch the C
+// callbacks to a handler and a C file which populates the table with them, and writes them to out.
+func (t *callbackTable) Generate(out Output) error {
+	name := strings.ToLower(t.Name) + "_handler_gen"
+
+	var b bytes.Buffer
+	if err := templateGenerateCallbackGoFile.Execute(&b, t); err != nil {
+		return err
+	}
+	if err := writeGoFile(out, name+".go", b.Bytes()); err != nil {
+		return err
+	}
+
+	b.Reset()
+	if err := templateGenerateCallbackCFile.Execute(&b, t); err != nil {
+		return err
+	}
+
+	return out.WriteFile(name+".c", b.Bytes())
+}
+
+// generateCallbackHandlers generates the handlers of all callback tables. Tables with callbacks which cannot be
+// dispatched to Go are skipped and added to the report.
+func (g *Generation) generateCallbackHandlers(renames map[string]string) error {
+	g.callbackTables = map[string]bool{}
+
+	for _, s := range g.structs {
+		for _, cb := range s.Callbacks {
+			if name, ok := renames[cb.ReturnType.GoName]; ok {
+				cb.ReturnType.GoName = name
+			}
+			for i := range cb.Parameters {
+				if name, ok := renames[cb.Parameters[i].Type.GoName]; ok {
+					cb.Parameters[i].Type.GoName = name
+				}
+			}
+		}
+
+		if !g.IsCallbackTable(s) {
+			continue
+		}
+
+		t, err := g.newCallbackTable(s)
+		if err != nil {
+			g.report.Add(ReportSkipped, s.CName, "cannot generate callback handler: %v", err)
+
+			continue
+		}
+
+		if err := t.Generate(g.cgoOutput()); err != nil {
+			return fmt.Errorf("cannot generate callback handler of %q: %w", s.CName, err)
+		}
+
+		g.callbackTables[s.Name] = true
+	}
+
+	return nil
+}
+
+// callbackTableParameter returns the index of the parameter of f which takes a callback table and reports whether f
+// takes one. The table must be preceded by its client data and followed by its size.
+func (g *Generation) callbackTableParameter(f *Function) (*Struct, int, bool) {
+	for i := 1; i+1 < len(f.Parameters); i++ {
+		p := f.Parameters[i]
+		if p.Type.PointerLevel != 1 {
+			continue
+		}
+
+		s, ok := g.HasStruct(p.Type.GoName)
+		if !ok || !g.callbackTables[s.Name] || p.Type.IsSlice {
+			continue
+		}
+
+		clientData, size := f.Parameters[i-1], f.Parameters[i+1]
+		if clientData.Type.GoName != "ClientData" || !IsInteger(&size.Type) || size.Type.PointerLevel != 0 {
+			continue
+		}
+
+		return s, i, true
+	}
+
+	return nil, 0, false
+}
+
+// GenerateCallbackTableWrapper generates a variant of the generated function src of f which takes a handler instead of
+// client data, a callback table and its size, e.g. IndexSourceFileWithHandler for IndexSourceFile.
+func (g *Generation) GenerateCallbackTableWrapper(f *Function, src string) (string, bool, error) {
+	s, i, ok := g.callbackTableParameter(f)
+	if !ok {
+		return "", false, nil
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package clang\n"+src, 0)
+	if err != nil {
+		return "", false, err
+	}
+
+	var fn *ast.FuncDecl
+	for _, d := range file.Decls {
+		if d, ok := d.(*ast.FuncDecl); ok {
+			fn = d
+		}
+	}
+	if fn == nil {
+		return "", false, fmt.Errorf("cannot find function of %q", f.CName)
+	}
+
+	replaced := map[string]ast.Expr{}
+	var sizeType ast.Expr
+	for _, field := range fn.Type.Params.List {
+		for _, n := range field.Names {
+			switch n.Name {
+			case f.Parameters[i-1].Name:
+				replaced[n.Name] = &ast.Ident{Name: "clientData"}
+
+			case f.Parameters[i].Name:
+				replaced[n.Name] = &ast.Ident{Name: "callbacks"}
+
+			case f.Parameters[i+1].Name:
+				sizeType = field.Type
+				replaced[n.Name] = nil
+			}
+		}
+	}
+	if len(replaced) != 3 {
+		return "", false, fmt.Errorf("cannot find the callback table parameters of %q", f.CName)
+	}
+	replaced[f.Parameters[i+1].Name] = &ast.CallExpr{
+		Fun: sizeType,
+		Args: []ast.Expr{
+			doCall("unsafe", "Sizeof", accessMember("callbacks", "c")),
+		},
+	}
+
+	params := &ast.FieldList{}
+	var args []ast.Expr
+	for _, field := range fn.Type.Params.List {
+		for _, n := range field.Names {
+			if r, ok := replaced[n.Name]; ok {
+				if n.Name == f.Parameters[i-1].Name {
+					params.List = append(params.List, &ast.Field{
+						Names: []*ast.Ident{{Name: "handler"}},
+						Type:  &ast.Ident{Name: s.Name + "Handler"},
+					})
+				}
+				args = append(args, r)
+
+				continue
+			}
+
+			params.List = append(params.List, &ast.Field{
+				Names: []*ast.Ident{{Name: n.Name}},
+				Type:  field.Type,
+			})
+			args = append(args, &ast.Ident{Name: n.Name})
+		}
+	}
+
+	var call ast.Expr = &ast.CallExpr{
+		Fun:  &ast.Ident{Name: fn.Name.Name},
+		Args: args,
+	}
+	if fn.Recv != nil {
+		call.(*ast.CallExpr).Fun = accessMember(fn.Recv.List[0].Names[0].Name, fn.Name.Name)
+	}
+
+	var callStmt ast.Stmt = &ast.ExprStmt{X: call}
+	if fn.Type.Results != nil && len(fn.Type.Results.List) > 0 {
+		callStmt = &ast.ReturnStmt{Results: []ast.Expr{call}}
+	}
+
+	wrapper := &ast.FuncDecl{
+		Recv: fn.Recv,
+		Name: &ast.Ident{Name: fn.Name.Name + "WithHandler"},
+		Type: &ast.FuncType{
+			Params:  params,
+			Results: fn.Type.Results,
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{
+						&ast.Ident{Name: "callbacks"},
+						&ast.Ident{Name: "clientData"},
+						&ast.Ident{Name: "release"},
+					},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{
+						doCast("new"+s.Name, &ast.Ident{Name: "handler"}),
+					},
+				},
+				&ast.DeferStmt{
+					Call: doCast("release"),
+				},
+				callStmt,
+			},
+		},
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s calls %s with callbacks which dispatch to handler.\n", wrapper.Name.Name, fn.Name.Name)
+	if err := format.Node(&b, token.NewFileSet(), wrapper); err != nil {
+		return "", false, err
+	}
+
+	return b.String(), true, nil
+}
@@ -0,0 +1,217 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestIsCallbackTable(t *testing.T) {
+	t.Parallel()
+
+	clientData := gen.FunctionParameter{
+		Name: "clientData",
+		Type: gen.Type{GoName: "ClientData"},
+	}
+	file := gen.FunctionParameter{
+		Name: "f",
+		Type: gen.Type{GoName: "File"},
+	}
+
+	tests := map[string]struct {
+		s    *gen.Struct
+		want bool
+	}{
+		"NoCallbacks": {
+			s:    &gen.Struct{},
+			want: false,
+		},
+		"ClientData": {
+			s: &gen.Struct{
+				Callbacks: []*gen.StructCallback{
+					{Name: "EnteredMainFile", Parameters: []gen.FunctionParameter{clientData, file}},
+					{Name: "AbortQuery", Parameters: []gen.FunctionParameter{clientData}},
+				},
+			},
+			want: true,
+		},
+		"WithoutClientData": {
+			s: &gen.Struct{
+				Callbacks: []*gen.StructCallback{
+					{Name: "EnteredMainFile", Parameters: []gen.FunctionParameter{clientData, file}},
+					{Name: "Visit", Parameters: []gen.FunctionParameter{file}},
+				},
+			},
+			want: false,
+		},
+		"WithFields": {
+			s: &gen.Struct{
+				Fields: []*gen.StructField{{CName: "size"}},
+				Callbacks: []*gen.StructCallback{
+					{Name: "AbortQuery", Parameters: []gen.FunctionParameter{clientData}},
+				},
+			},
+			want: false,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g := gen.NewGeneration(&gen.API{})
+			if got := g.IsCallbackTable(tt.s); got != tt.want {
+				t.Fatalf("IsCallbackTable() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+// newCallbackGeneration returns a generation with the callback table IndexerCallbacks of the two callbacks abortQuery
+// and diagnostic, and the structs they reference.
+func newCallbackGeneration(api *gen.API) *gen.Generation {
+	clientData := gen.FunctionParameter{Name: "client_data", Type: gen.Type{CName: "CXClientData", CGoName: "CXClientData", GoName: "ClientData"}}
+	reserved := gen.FunctionParameter{Name: "reserved", Type: gen.Type{CName: "void *", CGoName: "void", GoName: "void", PointerLevel: 1}}
+
+	h := gen.NewHeaderFile(api, "Index.h", "testdata")
+	h.Structs = []*gen.Struct{
+		{Name: "ClientData", CName: "CXClientData", CNameIsTypeDef: true, IsPointerTypedef: true},
+		{Name: "DiagnosticSet", CName: "CXDiagnosticSet", CNameIsTypeDef: true, IsPointerTypedef: true},
+		{Name: "IndexAction", CName: "CXIndexAction", CNameIsTypeDef: true, IsPointerTypedef: true},
+		{
+			Name:           "IndexerCallbacks",
+			CName:          "IndexerCallbacks",
+			CNameIsTypeDef: true,
+			Callbacks: []*gen.StructCallback{
+				{
+					Name:       "AbortQuery",
+					CName:      "abortQuery",
+					Comment:    "// Called periodically to check whether indexing should be aborted.",
+					Parameters: []gen.FunctionParameter{clientData, reserved},
+					ReturnType: gen.Type{CName: "int", CGoName: gen.CInt, GoName: gen.GoInt32},
+				},
+				{
+					Name:  "Diagnostic",
+					CName: "diagnostic",
+					Parameters: []gen.FunctionParameter{
+						clientData,
+						{Name: "result", Type: gen.Type{CName: "CXDiagnosticSet", CGoName: "CXDiagnosticSet", GoName: "DiagnosticSet"}},
+						reserved,
+					},
+					ReturnType: gen.Type{CName: "void", GoName: "void"},
+				},
+			},
+		},
+	}
+
+	g := gen.NewGeneration(api)
+	g.AddHeaderFiles([]*gen.HeaderFile{h})
+
+	return g
+}
+
+func TestGeneration_GenerateCallbackHandlers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		backend gen.Backend
+		wantGo  string
+		wantC   string
+	}{
+		"cgo": {
+			backend: gen.BackendCgo,
+			wantGo:  "callback/indexercallbacks_handler_gen.go",
+			wantC:   "callback/indexercallbacks_handler_gen.c",
+		},
+		"dlopen": {
+			backend: gen.BackendDlopen,
+			wantGo:  "callback/indexercallbacks_handler_dlopen_gen.go",
+			wantC:   "callback/indexercallbacks_handler_dlopen_gen.c",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := gen.NewMemoryOutput()
+			g := newCallbackGeneration(&gen.API{Backend: tt.backend, Output: out})
+
+			if err := g.GenerateCallbackHandlers(); err != nil {
+				t.Fatalf("GenerateCallbackHandlers() error = %v", err)
+			}
+			if diff := cmp.Diff([]gen.ReportEntry(nil), g.Report().Entries); diff != "" {
+				t.Fatalf("GenerateCallbackHandlers() report: (-want +got):\n%s", diff)
+			}
+
+			checkGolden(t, tt.wantGo, out.Files()["indexercallbacks_handler_gen.go"])
+			checkGolden(t, tt.wantC, out.Files()["indexercallbacks_handler_gen.c"])
+		})
+	}
+}
+
+func TestGeneration_GenerateCallbackTableWrapper(t *testing.T) {
+	t.Parallel()
+
+	indexAction := gen.Type{CName: "CXIndexAction", CGoName: "CXIndexAction", GoName: "IndexAction"}
+	f := &gen.Function{
+		Name:  "IndexSourceFile",
+		CName: "clang_indexSourceFile",
+		Parameters: []gen.FunctionParameter{
+			{Name: "ia", Type: indexAction},
+			{Name: "clientData", Type: gen.Type{CName: "CXClientData", CGoName: "CXClientData", GoName: "ClientData"}},
+			{Name: "indexCallbacks", Type: gen.Type{CName: "IndexerCallbacks *", CGoName: "IndexerCallbacks", GoName: "IndexerCallbacks", PointerLevel: 1}},
+			{Name: "indexCallbacksSize", Type: gen.Type{CName: "unsigned int", CGoName: gen.CUInt, GoName: gen.GoUInt32}},
+			{Name: "indexOptions", Type: gen.Type{CName: "unsigned int", CGoName: gen.CUInt, GoName: gen.GoUInt32}},
+		},
+		ReturnType: gen.Type{CName: "int", CGoName: gen.CInt, GoName: gen.GoInt32},
+		Receiver:   gen.Receiver{Name: "ia", Type: indexAction},
+	}
+	src := `// IndexSourceFile indexes the given source file.
+func (ia IndexAction) IndexSourceFile(clientData ClientData, indexCallbacks *IndexerCallbacks, indexCallbacksSize uint32, indexOptions uint32) int32 {
+	return int32(C.clang_indexSourceFile(ia.c, clientData.c, &indexCallbacks.c, C.uint(indexCallbacksSize), C.uint(indexOptions)))
+}`
+
+	tests := map[string]struct {
+		handlers bool
+		want     string
+		wantOK   bool
+	}{
+		"callback table": {
+			handlers: true,
+			want:     "callback/indexsourcefile_with_handler.go",
+			wantOK:   true,
+		},
+		"callback table without handler": {
+			handlers: false,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g := newCallbackGeneration(&gen.API{Output: gen.NewMemoryOutput()})
+			if tt.handlers {
+				if err := g.GenerateCallbackHandlers(); err != nil {
+					t.Fatalf("GenerateCallbackHandlers() error = %v", err)
+				}
+			}
+
+			got, ok, err := g.GenerateCallbackTableWrapper(f, src)
+			if err != nil {
+				t.Fatalf("GenerateCallbackTableWrapper() error = %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("GenerateCallbackTableWrapper() ok = %t, want %t", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+
+			checkGolden(t, tt.want, []byte(got+"\n"))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CgoCheck defines how the generation handles parameters which would pass Go pointers to C.
+type CgoCheck int
+
+const (
+	// CgoCheckOff does not audit the generated functions.
+	CgoCheckOff CgoCheck = iota
+	// CgoCheckFail fails the generation if a generated function passes Go memory containing Go pointers to C.
+	CgoCheckFail
+	// CgoCheckRewrite copies the affected slices into C allocated buffers and fails the generation only for slices which
+	// cannot be copied.
+	CgoCheckRewrite
+)
+
+// CgoPointerError is returned if a generated function would pass Go memory containing Go pointers to C.
+type CgoPointerError struct {
+	Function  string
+	Parameter string
+	Reason    string
+}
+
+// Error implements the error interface.
+func (e *CgoPointerError) Error() string {
+	return fmt.Sprintf("parameter %q of %q passes Go pointers to C: %s", e.Parameter, e.Function, e.Reason)
+}
+
+// CgoPointerErrors holds all violations found by a generation.
+type CgoPointerErrors []*CgoPointerError
+
+// Error implements the error interface.
+func (e CgoPointerErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// hasGoPointerElements reports whether the slice typ is passed to C as Go memory which contains Go pointers.
+func hasGoPointerElements(typ Type) bool {
+	if !typ.IsSlice || typ.IsReturnArgument {
+		return false
+	}
+
+	// strings are always copied into C memory
+	if typ.CGoName == CSChar && typ.PointerLevel >= 1 {
+		return false
+	}
+
+	return typ.PointerLevel > 1 || typ.GoName == GoPointer
+}
+
+// CheckCgoPointers audits the parameters of f for Go memory containing Go pointers which would be passed to C.
+//
+// Depending on the CgoCheck mode of the API violations are returned or rewritten to C allocated buffers. Only slices of
+// pointers to pointer compositions can be rewritten since their elements hold the C pointers themselves.
+func (g *Generation) CheckCgoPointers(f *Function) []*CgoPointerError {
+	if g.api.CgoCheck == CgoCheckOff || f.Member != nil {
+		return nil
+	}
+
+	var errs []*CgoPointerError
+	for i := range f.Parameters {
+		p := &f.Parameters[i]
+		if !hasGoPointerElements(p.Type) {
+			continue
+		}
+
+		if g.api.CgoCheck == CgoCheckRewrite && p.Type.PointerLevel == 2 && p.Type.IsPointerComposition {
+			p.Type.IsCopiedToC = true
+
+			continue
+		}
+
+		reason := "slice elements are Go pointers"
+		if g.api.CgoCheck == CgoCheckRewrite {
+			reason += " which cannot be copied into C memory"
+		}
+
+		errs = append(errs, &CgoPointerError{
+			Function:  f.CName,
+			Parameter: p.CName,
+			Reason:    reason,
+		})
+	}
+
+	return errs
+}
@@ -0,0 +1,75 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/go-clang/gen"
+)
+
+func TestCheckCgoPointers(t *testing.T) {
+	t.Parallel()
+
+	newFunction := func(typ gen.Type) *gen.Function {
+		return &gen.Function{
+			CName: "clang_test",
+			Parameters: []gen.FunctionParameter{
+				{Name: "s", CName: "s", Type: typ},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		mode       gen.CgoCheck
+		typ        gen.Type
+		wantErrs   int
+		wantCopied bool
+	}{
+		"off": {
+			mode:     gen.CgoCheckOff,
+			typ:      gen.Type{GoName: "Cursor", PointerLevel: 2, IsSlice: true},
+			wantErrs: 0,
+		},
+		"slice of values": {
+			mode:     gen.CgoCheckFail,
+			typ:      gen.Type{GoName: "Cursor", PointerLevel: 1, IsSlice: true},
+			wantErrs: 0,
+		},
+		"slice of strings": {
+			mode:     gen.CgoCheckFail,
+			typ:      gen.Type{GoName: "string", CGoName: gen.CSChar, PointerLevel: 2, IsSlice: true},
+			wantErrs: 0,
+		},
+		"slice of pointers": {
+			mode:     gen.CgoCheckFail,
+			typ:      gen.Type{GoName: "Cursor", PointerLevel: 2, IsSlice: true},
+			wantErrs: 1,
+		},
+		"rewrite slice of pointer compositions": {
+			mode:       gen.CgoCheckRewrite,
+			typ:        gen.Type{GoName: "IdxDeclInfo", PointerLevel: 2, IsSlice: true, IsPointerComposition: true},
+			wantErrs:   0,
+			wantCopied: true,
+		},
+		"rewrite slice of pointers": {
+			mode:     gen.CgoCheckRewrite,
+			typ:      gen.Type{GoName: "Cursor", PointerLevel: 2, IsSlice: true},
+			wantErrs: 1,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g := gen.NewGeneration(&gen.API{CgoCheck: tt.mode})
+			f := newFunction(tt.typ)
+
+			if errs := g.CheckCgoPointers(f); len(errs) != tt.wantErrs {
+				t.Fatalf("CheckCgoPointers() returned %d errors, want %d: %v", len(errs), tt.wantErrs, errs)
+			}
+			if got := f.Parameters[0].Type.IsCopiedToC; got != tt.wantCopied {
+				t.Fatalf("IsCopiedToC = %v, want %v", got, tt.wantCopied)
+			}
+		})
+	}
+}
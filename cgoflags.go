@@ -0,0 +1,103 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultUmbrellaHeader is the C header which is included by the generated files if API.UmbrellaHeader is empty.
+const DefaultUmbrellaHeader = "go-clang.h"
+
+const (
+	cgoFlagsFilename        = "cgoflags_gen.go"
+	cgoFlagsDynamicFilename = "cgoflags_dynamic_gen.go"
+)
+
+var templateGenerateCgoFlagsFile = template.Must(template.New("go-clang-generate-cgoflags-file").Parse(`package clang
+
+{{if $.CFlags}}// #cgo CFLAGS: {{$.CFlags}}
+{{end}}{{if $.LDFlags}}// #cgo LDFLAGS: {{$.LDFlags}}
+{{end}}{{if $.PkgConfig}}// #cgo pkg-config: {{$.PkgConfig}}
+{{end}}import "C"
+`))
+
+// cgoFlags holds the arguments of the #cgo directives of a generated file.
+type cgoFlags struct {
+	CFlags    string
+	LDFlags   string
+	PkgConfig string
+}
+
+// umbrellaHeader returns the C header which is included by the generated files.
+func (g *Generation) umbrellaHeader() string {
+	if g.api.UmbrellaHeader != "" {
+		return g.api.UmbrellaHeader
+	}
+
+	return DefaultUmbrellaHeader
+}
+
+// hasCgoFlags reports whether a defines cgo flags for the generated bindings.
+func (a *API) hasCgoFlags() bool {
+	return len(a.CFlags) > 0 || len(a.LDFlags) > 0 || len(a.PkgConfig) > 0
+}
+
+// cgoDirectiveArguments joins args to the arguments of a #cgo directive.
+func cgoDirectiveArguments(directive string, args []string) (string, error) {
+	for _, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\r\n") {
+			return "", fmt.Errorf("invalid %s argument %q", directive, a)
+		}
+	}
+
+	return strings.Join(args, " "), nil
+}
+
+// GenerateCgoFlags generates a Go file with the cgo CFLAGS of API and a Go file with its LDFLAGS and pkg-config
+// packages. The latter is excluded by the "static" build tag, whose builds link libclang statically instead.
+func (g *Generation) GenerateCgoFlags() error {
+	var data cgoFlags
+
+	var err error
+	if data.CFlags, err = cgoDirectiveArguments("CFLAGS", g.api.CFlags); err != nil {
+		return err
+	}
+	if data.LDFlags, err = cgoDirectiveArguments("LDFLAGS", g.api.LDFlags); err != nil {
+		return err
+	}
+	if data.PkgConfig, err = cgoDirectiveArguments("pkg-config", g.api.PkgConfig); err != nil {
+		return err
+	}
+
+	// the CFLAGS are used by static builds as well
+	static := data
+	static.LDFlags, static.PkgConfig = "", ""
+	dynamic := data
+	dynamic.CFlags = ""
+
+	for _, f := range []struct {
+		name string
+		data cgoFlags
+		out  Output
+	}{
+		{cgoFlagsFilename, static, g.output()},
+		{cgoFlagsDynamicFilename, dynamic, g.constrainedOutput("!static")},
+	} {
+		if f.data == (cgoFlags{}) {
+			continue
+		}
+
+		var b bytes.Buffer
+		if err := templateGenerateCgoFlagsFile.Execute(&b, f.data); err != nil {
+			return err
+		}
+
+		if err := writeGoFile(f.out, f.name, b.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
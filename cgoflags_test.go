@@ -0,0 +1,79 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestGeneration_GenerateCgoFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		api     gen.API
+		want    map[string]string
+		wantErr bool
+	}{
+		"cflags": {
+			api: gen.API{CFlags: []string{"-I/opt/llvm/include", "-DNDEBUG"}},
+			want: map[string]string{
+				"cgoflags_gen.go": "package clang\n\n" +
+					"// #cgo CFLAGS: -I/opt/llvm/include -DNDEBUG\n" +
+					"import \"C\"\n",
+			},
+		},
+		"versioned library": {
+			api: gen.API{LDFlags: []string{"-L/opt/llvm/lib", "-lclang-15"}},
+			want: map[string]string{
+				"cgoflags_dynamic_gen.go": "//go:build !static\n// +build !static\n\n" +
+					"package clang\n\n" +
+					"// #cgo LDFLAGS: -L/opt/llvm/lib -lclang-15\n" +
+					"import \"C\"\n",
+			},
+		},
+		"all": {
+			api: gen.API{CFlags: []string{"-DNDEBUG"}, LDFlags: []string{"-lclang-15"}, PkgConfig: []string{"libclang"}},
+			want: map[string]string{
+				"cgoflags_gen.go": "package clang\n\n" +
+					"// #cgo CFLAGS: -DNDEBUG\n" +
+					"import \"C\"\n",
+				"cgoflags_dynamic_gen.go": "//go:build !static\n// +build !static\n\n" +
+					"package clang\n\n" +
+					"// #cgo LDFLAGS: -lclang-15\n" +
+					"// #cgo pkg-config: libclang\n" +
+					"import \"C\"\n",
+			},
+		},
+		"flag with space": {
+			api:     gen.API{LDFlags: []string{"-L/opt/my llvm/lib"}},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := gen.NewMemoryOutput()
+			tt.api.Output = out
+
+			err := gen.NewGeneration(&tt.api).GenerateCgoFlags()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateCgoFlags() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := map[string]string{}
+			for name, data := range out.Files() {
+				got[name] = string(data)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("GenerateCgoFlags(): (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
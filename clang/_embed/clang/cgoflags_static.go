@@ -1,4 +0,0 @@
-//go:build static
-// +build static
-
-package clang
@@ -2,6 +2,7 @@ package clang
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -45,6 +46,45 @@ func TestBasicParsing(t *testing.T) {
 	}
 }
 
+func TestCursorIterators(t *testing.T) {
+	idx := NewIndex(0, 1)
+	defer idx.Dispose()
+
+	tu := idx.ParseTranslationUnit("../testdata/basicparsing.c", nil, nil, 0)
+	if !tu.IsValid() {
+		t.Fatal("tu is invalid")
+	}
+	defer tu.Dispose()
+
+	var children []string
+	for cursor := range tu.TranslationUnitCursor().Children() {
+		if cursor.Kind() == Cursor_FunctionDecl {
+			children = append(children, cursor.Spelling())
+		}
+	}
+	if !reflect.DeepEqual([]string{"foo"}, children) {
+		t.Fatalf("want [foo] but got %v", children)
+	}
+
+	found := 0
+	for cursor, parent := range tu.TranslationUnitCursor().Walk() {
+		if cursor.Kind() != Cursor_ParmDecl {
+			continue
+		}
+
+		if "bar" != cursor.Spelling() || "foo" != parent.Spelling() {
+			t.Fatalf("want bar of foo but got %s of %s", cursor.Spelling(), parent.Spelling())
+		}
+
+		found++
+
+		break
+	}
+	if found != 1 {
+		t.Fatal("Did not find all nodes")
+	}
+}
+
 func TestReparse(t *testing.T) {
 	us := []UnsavedFile{
 		NewUnsavedFile("hello.cpp", "int world();"),
@@ -92,3 +132,41 @@ func TestReparse(t *testing.T) {
 		t.Error("Expected to find 'world2', but didn't")
 	}
 }
+
+func TestInclusions(t *testing.T) {
+	idx := NewIndex(0, 1)
+	defer idx.Dispose()
+
+	tu := idx.ParseTranslationUnit("../testdata/hello.c", nil, nil, 0)
+	if !tu.IsValid() {
+		t.Fatal("tu is invalid")
+	}
+	defer tu.Dispose()
+
+	mainFile := 0
+	stdio := 0
+	tu.Inclusions(func(includedFile File, inclusionStack []SourceLocation) {
+		switch {
+		case len(inclusionStack) == 0:
+			mainFile++
+
+		case len(inclusionStack) == 1 && strings.HasSuffix(includedFile.Name(), "stdio.h"):
+			stdio++
+		}
+	})
+
+	if mainFile != 1 || stdio != 1 {
+		t.Fatalf("want the main file and stdio.h once but got %d and %d", mainFile, stdio)
+	}
+}
+
+func TestExecuteOnThread(t *testing.T) {
+	executed := false
+	ExecuteOnThread(func() {
+		executed = true
+	}, 8<<20)
+
+	if !executed {
+		t.Fatal("function was not executed")
+	}
+}
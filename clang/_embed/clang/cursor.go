@@ -3,6 +3,7 @@ package clang
 // #include "go-clang.h"
 import "C"
 import (
+	"iter"
 	"sync"
 	"unsafe"
 )
@@ -58,14 +59,15 @@ func (c Cursor) PlatformAvailability(availabilitySize int) (alwaysDeprecated boo
 // to direct clang_visitCursorChildren().
 type CursorVisitor func(cursor, parent Cursor) (status ChildVisitResult)
 
-type funcRegistry struct {
+// funcRegistry holds Go callbacks which are referenced from C by their index.
+type funcRegistry[F any] struct {
 	sync.RWMutex
 
 	index int
-	funcs map[int]*CursorVisitor
+	funcs map[int]*F
 }
 
-func (fm *funcRegistry) register(f *CursorVisitor) int {
+func (fm *funcRegistry[F]) register(f *F) int {
 	fm.Lock()
 	defer fm.Unlock()
 
@@ -79,14 +81,14 @@ func (fm *funcRegistry) register(f *CursorVisitor) int {
 	return fm.index
 }
 
-func (fm *funcRegistry) lookup(index int) *CursorVisitor {
+func (fm *funcRegistry[F]) lookup(index int) *F {
 	fm.RLock()
 	defer fm.RUnlock()
 
 	return fm.funcs[index]
 }
 
-func (fm *funcRegistry) unregister(index int) {
+func (fm *funcRegistry[F]) unregister(index int) {
 	fm.Lock()
 
 	delete(fm.funcs, index)
@@ -94,7 +96,7 @@ func (fm *funcRegistry) unregister(index int) {
 	fm.Unlock()
 }
 
-var visitors = &funcRegistry{
+var visitors = &funcRegistry[CursorVisitor]{
 	funcs: map[int]*CursorVisitor{},
 }
 
@@ -137,3 +139,33 @@ func (c Cursor) Visit(visitor CursorVisitor) bool {
 
 	return o == C.uint(0)
 }
+
+// Children returns an iterator over the direct children of c.
+//
+// Stopping the iteration early ends the traversal like a visitor returning ChildVisit_Break.
+func (c Cursor) Children() iter.Seq[Cursor] {
+	return func(yield func(Cursor) bool) {
+		c.Visit(func(cursor, _ Cursor) ChildVisitResult {
+			if !yield(cursor) {
+				return ChildVisit_Break
+			}
+
+			return ChildVisit_Continue
+		})
+	}
+}
+
+// Walk returns an iterator over all descendants of c in depth-first pre-order together with their parents.
+//
+// Stopping the iteration early ends the traversal like a visitor returning ChildVisit_Break.
+func (c Cursor) Walk() iter.Seq2[Cursor, Cursor] {
+	return func(yield func(cursor, parent Cursor) bool) {
+		c.Visit(func(cursor, parent Cursor) ChildVisitResult {
+			if !yield(cursor, parent) {
+				return ChildVisit_Break
+			}
+
+			return ChildVisit_Recurse
+		})
+	}
+}
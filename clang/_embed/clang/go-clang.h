@@ -6,5 +6,7 @@
 #include "clang-c/Index.h"
 
 unsigned go_clang_visit_children(CXCursor c, void *fct);
+void go_clang_get_inclusions(CXTranslationUnit tu, void *fct);
+void go_clang_execute_on_thread(void *fct, unsigned stackSize);
 
 #endif
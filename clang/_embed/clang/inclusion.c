@@ -0,0 +1,6 @@
+#include "_cgo_export.h"
+#include "go-clang.h"
+
+void go_clang_get_inclusions(CXTranslationUnit tu, void *fct) {
+	clang_getInclusions(tu, (CXInclusionVisitor)&GoClangInclusionVisitor, fct);
+}
@@ -0,0 +1,53 @@
+package clang
+
+// #include "go-clang.h"
+import "C"
+import (
+	"unsafe"
+)
+
+// InclusionVisitor invoked for each file included by a translation unit.
+//
+// This visitor function will be invoked by Inclusions for each file included (either at the top-level or by #include
+// directives) within a translation unit. The first argument is the file being included, and the second argument is
+// the stack of include locations of the file, beginning with the location of the #include directive which includes the
+// file directly. The stack is empty for the main file of the translation unit.
+type InclusionVisitor func(includedFile File, inclusionStack []SourceLocation)
+
+var inclusionVisitors = &funcRegistry[InclusionVisitor]{
+	funcs: map[int]*InclusionVisitor{},
+}
+
+// GoClangInclusionVisitor calls the inclusion visitor.
+//
+//export GoClangInclusionVisitor
+func GoClangInclusionVisitor(includedFile C.CXFile, inclusionStack *C.CXSourceLocation, includeLen C.uint, cfct unsafe.Pointer) {
+	i := *(*C.int)(cfct)
+	f := inclusionVisitors.lookup(int(i))
+
+	var stack []SourceLocation
+	if includeLen > 0 {
+		cs := unsafe.Slice(inclusionStack, int(includeLen))
+
+		stack = make([]SourceLocation, len(cs))
+		for i := range cs {
+			stack[i] = SourceLocation{cs[i]}
+		}
+	}
+
+	(*f)(File{includedFile}, stack)
+}
+
+// Inclusions visits the set of preprocessor inclusions in a translation unit.
+//
+// The visitor function is called with the included file and its inclusion stack for every included file. This does
+// not include headers included by the PCH file (unless one is inspecting the inclusions in the PCH file itself).
+func (tu TranslationUnit) Inclusions(visitor InclusionVisitor) {
+	i := inclusionVisitors.register(&visitor)
+	defer inclusionVisitors.unregister(i)
+
+	// we need a pointer to the index because clang_getInclusions data parameter is a void pointer.
+	ci := C.int(i)
+
+	C.go_clang_get_inclusions(tu.c, unsafe.Pointer(&ci))
+}
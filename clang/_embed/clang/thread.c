@@ -0,0 +1,6 @@
+#include "_cgo_export.h"
+#include "go-clang.h"
+
+void go_clang_execute_on_thread(void *fct, unsigned stackSize) {
+	clang_executeOnThread((void (*)(void *))&GoClangThreadFunc, fct, stackSize);
+}
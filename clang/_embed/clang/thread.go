@@ -0,0 +1,49 @@
+package clang
+
+// #include "go-clang.h"
+import "C"
+import (
+	"unsafe"
+)
+
+var threadFuncs = &funcRegistry[func()]{
+	funcs: map[int]*func(){},
+}
+
+// GoClangThreadFunc calls the function which is executed on a libclang thread.
+//
+//export GoClangThreadFunc
+func GoClangThreadFunc(cfct unsafe.Pointer) {
+	i := *(*C.int)(cfct)
+	f := threadFuncs.lookup(int(i))
+
+	(*f)()
+}
+
+// ExecuteOnThread runs fn on a separate thread of libclang with a stack of at least stackSize bytes and waits until it
+// returns. A stackSize of 0 selects the default stack size.
+//
+// This is useful for running recursive operations like parsing deeply nested code on a thread with a bigger stack. A
+// panic of fn is recovered on the libclang thread and raised again by ExecuteOnThread.
+func ExecuteOnThread(fn func(), stackSize uint32) {
+	var recovered interface{}
+	f := func() {
+		defer func() {
+			recovered = recover()
+		}()
+
+		fn()
+	}
+
+	i := threadFuncs.register(&f)
+	defer threadFuncs.unregister(i)
+
+	// we need a pointer to the index because clang_executeOnThread data parameter is a void pointer.
+	ci := C.int(i)
+
+	C.go_clang_execute_on_thread(unsafe.Pointer(&ci), C.uint(stackSize))
+
+	if recovered != nil {
+		panic(recovered)
+	}
+}
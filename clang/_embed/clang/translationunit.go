@@ -52,17 +52,3 @@ func (tu TranslationUnit) AnnotateTokens(Tokens []Token) []Cursor {
 func (tu TranslationUnit) IsValid() bool {
 	return tu.c != nil
 }
-
-// TODO this can be generated https://github.com/go-clang/gen/issues/47
-
-// Diagnostics determine the number of diagnostics produced prior to the
-// location where code completion was performed.
-func (tu TranslationUnit) Diagnostics() []Diagnostic {
-	s := make([]Diagnostic, tu.NumDiagnostics())
-
-	for i := range s {
-		s[i] = tu.Diagnostic(uint32(i))
-	}
-
-	return s
-}
@@ -1,11 +1,21 @@
 package clang
 
 import (
+	"bytes"
+	"context"
 	"embed"
 	"errors"
 	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -21,6 +31,8 @@ const (
 	clangDirName    = "clang"
 	clangCDirName   = "clang-c"
 	testdataDirName = "testdata"
+
+	cgoFlagsDynamicFilename = "cgoflags_dynamic.go"
 )
 
 var (
@@ -29,8 +41,8 @@ var (
 	embedTestdataDirPath = filepath.Join(embedDirRootPath, testdataDirName)
 )
 
-// Cmd executes a generic go-clang-generate command.
-func Cmd(llvmRoot string, api *gen.API) error {
+// Cmd executes a generic go-clang-generate command. It returns early if ctx is canceled.
+func Cmd(ctx context.Context, llvmRoot string, api *gen.API) error {
 	llvmConfigPath := filepath.Join(llvmRoot, "bin", "llvm-config")
 	if err := fileExists(llvmConfigPath); err != nil {
 		return err
@@ -47,6 +59,8 @@ func Cmd(llvmRoot string, api *gen.API) error {
 	}
 	fmt.Printf("detected the LLVM version: %s\n", llvmVersion)
 
+	api.LLVMVersion = llvmVersion.String()
+
 	rawLLVMIncludeDir, _, err := execToBuffer(llvmConfigPath, "--includedir")
 	if err != nil {
 		return fmt.Errorf("cannot determine LLVM include directory: %w", err)
@@ -92,15 +106,15 @@ func Cmd(llvmRoot string, api *gen.API) error {
 	fmt.Printf("using clang arguments: %v\n", api.ClangArguments)
 	fmt.Printf("will generate go-clang for %s version into the ./%s directory\n", llvmVersion, clangDirName)
 
-	// remove all generated _gen.go files
+	// remove all generated _gen.go, _gen.c and _gen.h files
 	oldGenFiles, err := os.ReadDir(clangDirPath)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("cannot read %s directory: %w", clangDirName, err)
 	}
 	for _, f := range oldGenFiles {
 		fname := f.Name()
-		if !f.IsDir() && strings.HasSuffix(fname, "_gen.go") {
-			if err := os.Remove(fname); err != nil && !os.IsNotExist(err) {
+		if !f.IsDir() && (strings.HasSuffix(fname, "_gen.go") || strings.HasSuffix(fname, "_gen.c") || strings.HasSuffix(fname, "_gen.h")) {
+			if err := os.Remove(filepath.Join(clangDirPath, fname)); err != nil && !os.IsNotExist(err) {
 				return fmt.Errorf("cannot remove %q generated file: %w", fname, err)
 			}
 		}
@@ -118,6 +132,18 @@ func Cmd(llvmRoot string, api *gen.API) error {
 		return fmt.Errorf("could not write embedded %s non-generated file: %w", embedClangDirPath, err)
 	}
 
+	// the linker flags of the API replace the default ones
+	if len(api.LDFlags) > 0 || len(api.PkgConfig) > 0 {
+		if err := os.Remove(filepath.Join(clangDirPath, cgoFlagsDynamicFilename)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot remove %s: %w", cgoFlagsDynamicFilename, err)
+		}
+	}
+
+	// write the link line of the static build tag, the bindings do not support it if LLVM has no static archives
+	if err := writeCgoFlagsStatic(clangDirPath, llvmConfigPath, api.HeaderTemplate); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot generate %s, builds with the static build tag are not supported: %v\n", cgoFlagsStaticFilename, err)
+	}
+
 	// write testdata files
 	if err := WriteEmbedFile(testdataDirPath, embedTestdataDirPath); err != nil {
 		return fmt.Errorf("could not write embedded %s testdata file: %w", embedTestdataDirPath, err)
@@ -146,21 +172,41 @@ func Cmd(llvmRoot string, api *gen.API) error {
 		return fmt.Errorf("could not write %s file: %w", clangCDocPath, err)
 	}
 
+	// the compile commands refer to the original headers instead of their copies
+	api.CompilationDatabaseHeaderDir = clangCIncludeDir
+
 	// handle Clang headers
-	headerFiles, err := api.HandleDirectory("./" + clangDirName + string(os.PathSeparator) + clangCDirName)
+	headerFiles, err := api.HandleDirectory(ctx, "./"+clangDirName+string(os.PathSeparator)+clangCDirName)
 	if err != nil {
 		return fmt.Errorf("could not handle clang-c header directory: %w", err)
 	}
 
+	// the embedded non-generated files call the generated functions with a single result
+	if api.NullHandling == gen.NullHandlingOK {
+		calls, err := embeddedCalls(ctx, *api, "./"+clangDirName+string(os.PathSeparator)+clangCDirName, embedClangDirPath)
+		if err != nil {
+			return fmt.Errorf("could not collect the functions called by embedded files: %w", err)
+		}
+
+		filter := api.FilterNullableFunction
+		api.FilterNullableFunction = func(f *gen.Function) bool {
+			return !calls[functionKey(f)] && (filter == nil || filter(f))
+		}
+	}
+
 	// initialize generator
 	generator := gen.NewGeneration(api)
 	generator.AddHeaderFiles(headerFiles)
 
 	// generation Clang binding
-	if err := generator.Generate(); err != nil {
+	if err := generator.Generate(ctx); err != nil {
 		return fmt.Errorf("could not generate: %w", err)
 	}
 
+	if _, err := generator.Report().WriteTo(os.Stdout); err != nil {
+		return fmt.Errorf("could not write generation report: %w", err)
+	}
+
 	return nil
 }
 
@@ -210,3 +256,178 @@ func WriteEmbedFile(dstDir, embedDir string) error {
 
 	return nil
 }
+
+// functionKey returns the key of f in the result of embeddedCalls.
+func functionKey(f *gen.Function) string {
+	if f.Receiver.Name == "" {
+		return f.Name
+	}
+
+	return f.Receiver.Type.GoName + "." + f.Name
+}
+
+// embeddedCalls returns the generated functions, e.g. "NewIndex", and methods, e.g. "Cursor.Spelling", which are
+// called or otherwise used by the embedded Go files of embedDir, directly or through other generated functions like
+// slice accessors.
+// The calls are resolved by type checking the embedded files together with the bindings of the headers of headerDir,
+// which are generated into memory with single results for this.
+func embeddedCalls(ctx context.Context, api gen.API, headerDir, embedDir string) (map[string]bool, error) {
+	out := gen.NewMemoryOutput()
+	api.NullHandling = gen.NullHandlingIsNull
+	api.Backend = gen.BackendCgo
+	api.Output = out
+
+	headerFiles, err := api.HandleDirectory(ctx, headerDir)
+	if err != nil {
+		return nil, err
+	}
+
+	generator := gen.NewGeneration(&api)
+	generator.AddHeaderFiles(headerFiles)
+
+	if err := generator.Generate(ctx); err != nil {
+		return nil, err
+	}
+
+	files := out.Files()
+	var roots []string
+
+	d, err := embedClang.ReadDir(embedDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s directory: %w", embedDir, err)
+	}
+
+	for _, ent := range d {
+		fname := ent.Name()
+		if ent.IsDir() || !strings.HasSuffix(fname, ".go") {
+			continue
+		}
+
+		data, err := embedClang.ReadFile(filepath.Join(embedDir, fname))
+		if err != nil {
+			return nil, fmt.Errorf("could not read embedded %s file: %w", fname, err)
+		}
+
+		files[fname] = data
+		roots = append(roots, fname)
+	}
+
+	return calledFunctions(files, roots)
+}
+
+// calledFunctions type checks the Go files of a package, which are keyed by their names, and returns the functions
+// and methods of the package which the files roots use, directly or through the bodies of other functions of the
+// package. Errors of the type checking are ignored since the C declarations of cgo files are unknown.
+func calledFunctions(files map[string][]byte, roots []string) (map[string]bool, error) {
+	bctx := build.Default
+	bctx.CgoEnabled = true
+	bctx.OpenFile = func(path string) (io.ReadCloser, error) {
+		data, ok := files[filepath.Base(path)]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	parsed := map[string]*ast.File{}
+	var astFiles []*ast.File
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+
+		if ok, err := bctx.MatchFile(".", name); err != nil {
+			return nil, fmt.Errorf("could not match the build constraints of %s: %w", name, err)
+		} else if !ok {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, name, files[name], parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", name, err)
+		}
+
+		parsed[name] = f
+		astFiles = append(astFiles, f)
+	}
+
+	conf := types.Config{
+		FakeImportC: true,
+		Importer:    importer.Default(),
+		Error:       func(error) {},
+	}
+	info := &types.Info{
+		Defs: map[*ast.Ident]types.Object{},
+		Uses: map[*ast.Ident]types.Object{},
+	}
+	pkg, _ := conf.Check(clangDirName, fset, astFiles, info)
+
+	bodies := map[types.Object]*ast.BlockStmt{}
+	for _, f := range astFiles {
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Body != nil {
+				if obj := info.Defs[fd.Name]; obj != nil {
+					bodies[obj] = fd.Body
+				}
+			}
+		}
+	}
+
+	calls := map[string]bool{}
+	var queue []ast.Node
+	for _, name := range roots {
+		if f, ok := parsed[name]; ok {
+			queue = append(queue, f)
+		}
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		ast.Inspect(n, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+
+			fn, ok := info.Uses[id].(*types.Func)
+			if !ok || fn.Pkg() != pkg {
+				return true
+			}
+
+			key := fn.Name()
+			if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+				typ := recv.Type()
+				if p, ok := typ.(*types.Pointer); ok {
+					typ = p.Elem()
+				}
+				named, ok := typ.(*types.Named)
+				if !ok {
+					return true
+				}
+				key = named.Obj().Name() + "." + key
+			}
+
+			if !calls[key] {
+				calls[key] = true
+				if body, ok := bodies[fn]; ok {
+					queue = append(queue, body)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return calls, nil
+}
@@ -0,0 +1,139 @@
+package clang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// calledFunctionsFiles is a package of generated bindings and hand-written files which use them.
+var calledFunctionsFiles = map[string][]byte{
+	"cursor_gen.go": []byte(`package clang
+
+// #include "go-clang.h"
+import "C"
+
+type Cursor struct {
+	c C.CXCursor
+}
+
+func (c Cursor) Spelling() string {
+	return ""
+}
+
+func (c Cursor) Type() Type {
+	return Type{C.clang_getCursorType(c.c)}
+}
+
+type Type struct {
+	c C.CXType
+}
+
+func (t Type) Spelling() string {
+	return ""
+}
+`),
+	"translationunit_gen.go": []byte(`package clang
+
+// #include "go-clang.h"
+import "C"
+
+type TranslationUnit struct {
+	c C.CXTranslationUnit
+}
+
+func NewTranslationUnit() TranslationUnit {
+	return TranslationUnit{}
+}
+
+func (tu TranslationUnit) NumDiagnostics() uint32 {
+	return 0
+}
+
+func (tu TranslationUnit) Diagnostic(i uint32) Cursor {
+	return Cursor{}
+}
+
+func (tu TranslationUnit) Diagnostics() []Cursor {
+	s := make([]Cursor, tu.NumDiagnostics())
+	for i := range s {
+		s[i] = tu.Diagnostic(uint32(i))
+	}
+
+	return s
+}
+`),
+	"windows_gen.go": []byte(`//go:build ignore
+
+package clang
+
+func (c Cursor) Type() string {
+	return ""
+}
+`),
+	"cursor.go": []byte(`package clang
+
+func (c Cursor) TypeSpelling() string {
+	return c.Type().Spelling()
+}
+`),
+	"translationunit_test.go": []byte(`package clang
+
+import "testing"
+
+func TestTranslationUnit(t *testing.T) {
+	tu := NewTranslationUnit()
+	for _, d := range tu.Diagnostics() {
+		t.Log(d)
+	}
+
+	spelling := Cursor{}.Spelling
+	t.Log(spelling())
+}
+`),
+}
+
+func TestCalledFunctions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		roots []string
+		want  map[string]bool
+	}{
+		"methods of the receiver type": {
+			roots: []string{"cursor.go"},
+			want: map[string]bool{
+				"Cursor.Type":   true,
+				"Type.Spelling": true,
+			},
+		},
+		"through generated functions": {
+			roots: []string{"translationunit_test.go"},
+			want: map[string]bool{
+				"NewTranslationUnit":             true,
+				"TranslationUnit.Diagnostics":    true,
+				"TranslationUnit.NumDiagnostics": true,
+				"TranslationUnit.Diagnostic":     true,
+				"Cursor.Spelling":                true,
+			},
+		},
+		"no roots": {
+			want: map[string]bool{},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := calledFunctions(calledFunctionsFiles, tt.roots)
+			if err != nil {
+				t.Fatalf("calledFunctions() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("calledFunctions(): (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
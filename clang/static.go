@@ -0,0 +1,134 @@
+package clang
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/go-clang/gen"
+)
+
+const cgoFlagsStaticFilename = "cgoflags_static_gen.go"
+
+// clangStaticLibraries holds the clang component archives libclang depends on, ordered so that every archive precedes
+// its dependencies as single-pass linkers require. Archives which do not exist in the LLVM library directory, e.g. since
+// they were introduced by a later LLVM version, are skipped.
+var clangStaticLibraries = []string{
+	"clang",
+	"clangExtractAPI",
+	"clangInstallAPI",
+	"clangIndex",
+	"clangTooling",
+	"clangFormat",
+	"clangToolingInclusions",
+	"clangToolingCore",
+	"clangFrontend",
+	"clangDriver",
+	"clangParse",
+	"clangSerialization",
+	"clangSema",
+	"clangAPINotes",
+	"clangEdit",
+	"clangRewrite",
+	"clangAnalysis",
+	"clangASTMatchers",
+	"clangAST",
+	"clangSupport",
+	"clangLex",
+	"clangBasic",
+}
+
+const cgoFlagsStaticTmpl = `package clang
+
+// #cgo LDFLAGS: %s
+import "C"
+`
+
+// staticArchiveFlag returns the linker flag which links the archive of the library name from the library search path.
+// The flags do not contain paths of the generating machine, except for the -L flags of llvm-config.
+func staticArchiveFlag(name string) string {
+	// the linker of macOS does not know "-l:", it links the archive as long as the directory has no shared library of
+	// the same name, i.e. libclang.dylib has to be moved out of the way for a static libclang
+	if runtime.GOOS == "darwin" {
+		return "-l" + name
+	}
+
+	// "-l" would prefer the shared libclang if both exist
+	return "-l:lib" + name + ".a"
+}
+
+// staticLDFlags queries llvm-config for the flags which link libclang, the clang component archives, the LLVM archives
+// and the system libraries statically. It fails if the LLVM installation has no static archives.
+func staticLDFlags(llvmConfigPath string) ([]string, error) {
+	// older versions of llvm-config do not know --link-static but always list the static archives, newer versions fail
+	// with the missing archives of the LLVM components
+	linkStatic := []string{"--link-static"}
+	if out, _, err := execToBuffer(llvmConfigPath, "--link-static", "--libs"); err != nil {
+		if strings.Contains(string(out), "missing:") {
+			return nil, fmt.Errorf("LLVM has no static archives: %s", strings.TrimSpace(string(out)))
+		}
+
+		linkStatic = nil
+	}
+
+	var flags []string
+	for _, arg := range []string{"--ldflags", "--libdir", "--libs", "--system-libs"} {
+		out, _, err := execToBuffer(append(append([]string{llvmConfigPath}, linkStatic...), arg)...)
+		if err != nil {
+			return nil, fmt.Errorf("cannot execute llvm-config %s: %w: %s", arg, err, out)
+		}
+
+		if arg != "--libdir" {
+			flags = append(flags, strings.Fields(string(out))...)
+
+			continue
+		}
+
+		// the clang archives are not LLVM components and therefore not listed by llvm-config
+		libDir := strings.TrimSpace(string(out))
+		if archive := filepath.Join(libDir, "libclang.a"); fileExists(archive) != nil {
+			return nil, fmt.Errorf("cannot find the static libclang %s", archive)
+		}
+
+		if libDirFlag := "-L" + libDir; !slices.Contains(flags, libDirFlag) {
+			flags = append(flags, libDirFlag)
+		}
+		for _, lib := range clangStaticLibraries {
+			if fileExists(filepath.Join(libDir, "lib"+lib+".a")) == nil {
+				flags = append(flags, staticArchiveFlag(lib))
+			}
+		}
+	}
+
+	// libclang is written in C++
+	if runtime.GOOS == "darwin" {
+		flags = append(flags, "-lc++")
+	} else {
+		flags = append(flags, "-lstdc++")
+	}
+
+	return flags, nil
+}
+
+// writeCgoFlagsStatic writes the cgo flags of the static build tag into the clang directory clangDirPath. The file
+// starts with the header of headerTmpl, see gen.Header. Like the -L flags of llvm-config, the flags only link on
+// machines whose LLVM library directory has the same path.
+func writeCgoFlagsStatic(clangDirPath string, llvmConfigPath string, headerTmpl *template.Template) error {
+	flags, err := staticLDFlags(llvmConfigPath)
+	if err != nil {
+		return err
+	}
+
+	header, err := gen.Header{Name: cgoFlagsStaticFilename, Constraint: "static"}.Generate(headerTmpl)
+	if err != nil {
+		return err
+	}
+
+	data := append(header, fmt.Sprintf(cgoFlagsStaticTmpl, strings.Join(flags, " "))...)
+
+	return os.WriteFile(filepath.Join(clangDirPath, cgoFlagsStaticFilename), data, 0644)
+}
@@ -0,0 +1,103 @@
+package clang
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeLLVMConfig is a llvm-config which lists the static LLVM archives of libDir. It runs the shell command linkStatic
+// for --link-static.
+const fakeLLVMConfig = `#!/bin/sh
+for arg; do
+	case "$arg" in
+	--link-static) %[2]s ;;
+	--ldflags) echo "-L%[1]s" ;;
+	--libdir) echo "%[1]s" ;;
+	--libs) echo "-lLLVMOption -lLLVMSupport" ;;
+	--system-libs) echo "-lz -lm" ;;
+	esac
+done
+`
+
+func TestStaticLDFlags(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake llvm-config is a shell script")
+	}
+
+	cxx := "-lstdc++"
+	if runtime.GOOS == "darwin" {
+		cxx = "-lc++"
+	}
+
+	tests := map[string]struct {
+		linkStatic string
+		libraries  []string
+		// want holds the names of the linked archives of libraries.
+		want    []string
+		wantErr bool
+	}{
+		"link static": {
+			linkStatic: "true",
+			libraries:  []string{"libclangBasic.a", "libclang.so", "libclang.a", "libclangIndex.a", "libclangExtractAPI.a"},
+			want:       []string{"clang", "clangExtractAPI", "clangIndex", "clangBasic"},
+		},
+		"without link static": {
+			linkStatic: "echo 'llvm-config: unknown option --link-static'; exit 1",
+			libraries:  []string{"libclangBasic.a", "libclang.a"},
+			want:       []string{"clang", "clangBasic"},
+		},
+		"missing LLVM archives": {
+			linkStatic: "echo 'llvm-config: error: missing: /usr/lib/libLLVMSupport.a'; exit 1",
+			libraries:  []string{"libclangBasic.a", "libclang.a"},
+			wantErr:    true,
+		},
+		"missing libclang archive": {
+			linkStatic: "true",
+			libraries:  []string{"libclangBasic.a", "libclang.so"},
+			wantErr:    true,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			libDir := t.TempDir()
+			for _, l := range tt.libraries {
+				if err := os.WriteFile(filepath.Join(libDir, l), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			llvmConfigPath := filepath.Join(t.TempDir(), "llvm-config")
+			if err := os.WriteFile(llvmConfigPath, []byte(fmt.Sprintf(fakeLLVMConfig, libDir, tt.linkStatic)), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := staticLDFlags(llvmConfigPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("staticLDFlags() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			want := []string{"-L" + libDir}
+			for _, l := range tt.want {
+				want = append(want, staticArchiveFlag(l))
+			}
+			want = append(want, "-lLLVMOption", "-lLLVMSupport", "-lz", "-lm", cxx)
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("staticLDFlags(): (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
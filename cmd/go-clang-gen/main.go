@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/go-clang/gen"
 	genclang "github.com/go-clang/gen/clang"
@@ -14,11 +19,46 @@ import (
 )
 
 var (
-	flagLLVMRoot string
+	flagLLVMRoot             string
+	flagBackend              string
+	flagAvailability         string
+	flagResolveNameConflicts bool
+	flagGoInitialisms        bool
+	flagCopyCArrays          bool
+	flagCgoCheck             string
+	flagInferOutParameters   bool
+	flagNullHandling         string
+	flagTemplateDir          string
+	flagUmbrellaHeader       string
+	flagCFlags               string
+	flagLDFlags              string
+	flagPkgConfig            string
+	flagCompilationDatabase  string
+)
+
+const (
+	// fileTemplateName is the name of the file template in the template directory.
+	fileTemplateName = "file.go.tmpl"
+	// headerTemplateName is the name of the header template in the template directory.
+	headerTemplateName = "header.tmpl"
 )
 
 func init() {
 	flag.StringVar(&flagLLVMRoot, "llvm-root", "", "path of llvm root directory")
+	flag.StringVar(&flagBackend, "backend", "cgo", "backend of the generated bindings, \"cgo\" or \"dlopen\" which additionally binds the functions without structs passed by value like CXCursor and CXString for builds without cgo")
+	flag.StringVar(&flagAvailability, "availability", "", "comma separated list of older LLVM versions and their clang-c header directory ordered from the oldest, e.g. \"9=/usr/lib/llvm-9/include/clang-c\"")
+	flag.BoolVar(&flagResolveNameConflicts, "resolve-name-conflicts", false, "rename functions which map to the same Go name instead of failing")
+	flag.StringVar(&flagCgoCheck, "cgocheck", "off", "handling of functions passing Go pointers to C, \"off\", \"fail\" or \"rewrite\"")
+	flag.BoolVar(&flagCopyCArrays, "copy-c-arrays", false, "copy C arrays which are returned as slices into Go memory")
+	flag.BoolVar(&flagInferOutParameters, "infer-out-parameters", false, "mark pointer parameters which are documented as outputs as return arguments")
+	flag.StringVar(&flagNullHandling, "null-handling", "none", "handling of NULL C pointers wrapped by structs, \"none\", \"is-null\" or \"ok\"")
+	flag.StringVar(&flagTemplateDir, "template-dir", "", "directory with templates overriding the default ones, \""+fileTemplateName+"\" for the generated Go files and \""+headerTemplateName+"\" for the header of every generated file")
+	flag.StringVar(&flagUmbrellaHeader, "umbrella-header", gen.DefaultUmbrellaHeader, "C header which is included by every generated file, the non-generated files keep including \""+gen.DefaultUmbrellaHeader+"\"")
+	flag.StringVar(&flagCFlags, "cflags", "", "space separated cgo CFLAGS of the generated bindings")
+	flag.StringVar(&flagLDFlags, "ldflags", "", "space separated cgo LDFLAGS of the generated bindings replacing the default \"-lclang\", e.g. \"-L/opt/llvm/lib -lclang-15\"")
+	flag.StringVar(&flagPkgConfig, "pkg-config", "", "space separated pkg-config packages of the generated bindings replacing the default \"-lclang\"")
+	flag.StringVar(&flagCompilationDatabase, "compilation-database", "", "build directory of a compile_commands.json whose compile commands are used to parse the headers, which are looked up by the paths of the headers in the LLVM include directory, headers without a command are parsed with the first command in the language of its source file")
+	flag.BoolVar(&flagGoInitialisms, "go-initialisms", false, "apply Go initialisms like \"USR\" to the generated identifiers")
 }
 
 func main() {
@@ -32,6 +72,87 @@ func main() {
 		FixFunctionName:         runtime.FixFunctionName,
 		PrepareStructFields:     runtime.PrepareStructFields,
 		FilterStructFieldGetter: runtime.FilterStructFieldGetter,
+		ResolveNameConflicts:    flagResolveNameConflicts,
+		CopyCArrays:             flagCopyCArrays,
+		InferOutParameters:      flagInferOutParameters,
+		UmbrellaHeader:          flagUmbrellaHeader,
+		CFlags:                  strings.Fields(flagCFlags),
+		LDFlags:                 strings.Fields(flagLDFlags),
+		PkgConfig:               strings.Fields(flagPkgConfig),
+		CompilationDatabase:     flagCompilationDatabase,
+	}
+
+	if flagGoInitialisms {
+		api.Initialisms = gen.CommonInitialisms
+	}
+
+	switch flagBackend {
+	case "cgo":
+		api.Backend = gen.BackendCgo
+
+	case "dlopen":
+		api.Backend = gen.BackendDlopen
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown backend %q\n", flagBackend)
+		os.Exit(1)
+	}
+
+	switch flagCgoCheck {
+	case "off":
+		api.CgoCheck = gen.CgoCheckOff
+
+	case "fail":
+		api.CgoCheck = gen.CgoCheckFail
+
+	case "rewrite":
+		api.CgoCheck = gen.CgoCheckRewrite
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown cgocheck mode %q\n", flagCgoCheck)
+		os.Exit(1)
+	}
+
+	switch flagNullHandling {
+	case "none":
+		api.NullHandling = gen.NullHandlingNone
+
+	case "is-null":
+		api.NullHandling = gen.NullHandlingIsNull
+
+	case "ok":
+		api.NullHandling = gen.NullHandlingOK
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown null handling %q\n", flagNullHandling)
+		os.Exit(1)
+	}
+
+	if flagTemplateDir != "" {
+		var err error
+		if api.FileTemplate, err = readTemplate(fileTemplateName, gen.NewFileTemplate); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if api.HeaderTemplate, err = readTemplate(headerTemplateName, gen.NewHeaderTemplate); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if flagAvailability != "" {
+		for _, t := range strings.Split(flagAvailability, ",") {
+			vd := strings.SplitN(t, "=", 2)
+			if len(vd) != 2 {
+				fmt.Fprintf(os.Stderr, "invalid availability tree %q\n", t)
+				os.Exit(1)
+			}
+
+			api.AvailabilityTrees = append(api.AvailabilityTrees, gen.AvailabilityTree{
+				Version: vd[0],
+				Dir:     vd[1],
+			})
+		}
 	}
 
 	if flagLLVMRoot == "" {
@@ -56,8 +177,30 @@ func main() {
 		}
 	}
 
-	if err := genclang.Cmd(flagLLVMRoot, api); err != nil {
+	// cancel the generation on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := genclang.Cmd(ctx, flagLLVMRoot, api)
+	stop()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// readTemplate parses the template name of the template directory with parse. It returns nil if the template directory
+// does not contain the template.
+func readTemplate(name string, parse func(text string) (*template.Template, error)) (*template.Template, error) {
+	text, err := os.ReadFile(filepath.Join(flagTemplateDir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %q: %w", name, err)
+	}
+
+	return tmpl, nil
+}
@@ -75,9 +75,12 @@ func PrepareFunction(f *gen.Function) {
 			continue
 		}
 
-		// allowflag types that are return arguments
-		switch p.Type.PointerLevel {
-		case 1:
+		// allowflag types that are return arguments, values which are only readable through the pointer are always inputs
+		switch pl := p.Type.PointerLevel; {
+		case p.Type.IsConstAt(1):
+			// nothing to do
+
+		case pl == 1:
 			switch p.Type.GoName {
 			case
 				gen.GoInt32,
@@ -94,7 +97,7 @@ func PrepareFunction(f *gen.Function) {
 				p.Type.IsReturnArgument = true
 			}
 
-		case 2:
+		case pl == 2:
 			switch p.Type.GoName {
 			case
 				"Token",
@@ -146,7 +149,7 @@ func PrepareFunction(f *gen.Function) {
 	for i := range f.Parameters {
 		p := &f.Parameters[i]
 
-		if p.Type.CGoName == gen.CSChar && p.Type.PointerLevel == 2 && !p.Type.IsSlice {
+		if p.Type.CGoName == gen.CSChar && p.Type.PointerLevel == 2 && !p.Type.IsSlice && !p.Type.IsConstAt(1) {
 			p.Type.IsReturnArgument = true
 		}
 	}
@@ -161,13 +164,7 @@ func FilterFunction(f *gen.Function) bool {
 
 		return false
 
-	case "clang_executeOnThread", "clang_getInclusions":
-		// some functions can not be handled automatically by us
-		fmt.Fprintf(os.Stderr, "Ignore function %q because it cannot be handled automatically\n", f.CName)
-
-		return false
-
-	case "clang_annotateTokens", "clang_getCursorPlatformAvailability", "clang_visitChildren":
+	case "clang_annotateTokens", "clang_executeOnThread", "clang_getCursorPlatformAvailability", "clang_getInclusions", "clang_visitChildren":
 		// some functions are simply manually implemented
 		fmt.Fprintf(os.Stderr, "Ignore function %q because it is manually implemented\n", f.CName)
 
@@ -0,0 +1,160 @@
+package gen
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-clang/bootstrap/clang"
+)
+
+// pathFlags holds the flags which are followed by a path, either joined or as separate argument. The path of a flag
+// starting with "--" is joined by "=", e.g. "--sysroot=/opt/sysroot".
+var pathFlags = []string{"-I", "-isystem", "-iquote", "-idirafter", "-iframework", "-F", "-include", "-include-pch", "-imacros", "-isysroot", "--sysroot"}
+
+// valueFlags holds the flags whose value is a separate argument, in addition to pathFlags and "-o".
+var valueFlags = []string{"-x", "-D", "-U", "-arch", "-target", "-Xclang", "-Xpreprocessor", "-MF", "-MT", "-MQ"}
+
+// sourceLanguages maps the extensions of source files to their languages, see the "-x" flag of Clang.
+var sourceLanguages = map[string]string{
+	".c":   "c",
+	".cc":  "c++",
+	".cpp": "c++",
+	".cxx": "c++",
+	".c++": "c++",
+	".C":   "c++",
+	".m":   "objective-c",
+	".mm":  "objective-c++",
+}
+
+// CompileCommandArguments returns the Clang arguments of a command line args of a compilation database which compiles
+// the source file filename in directory. The compiler, the source file, "-c" and the output file are dropped and paths
+// of include directories, included files and the sysroot are made absolute, since the arguments are used to parse a
+// different file.
+func CompileCommandArguments(directory string, filename string, args []string) []string {
+	abs := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+
+		return filepath.Join(directory, path)
+	}
+
+	var arguments []string
+
+	for i := 1; i < len(args); i++ {
+		a := args[i]
+
+		// the compile flag, the output file and the source file are dropped
+		switch {
+		case a == "-c":
+		case a == "-o":
+			i++
+		case strings.HasPrefix(a, "-o") && !strings.HasPrefix(a, "-objc"):
+		case filename != "" && abs(a) == abs(filename):
+		case slices.Contains(pathFlags, a) && i+1 < len(args):
+			arguments = append(arguments, a, abs(args[i+1]))
+			i++
+		case slices.Contains(valueFlags, a) && i+1 < len(args):
+			arguments = append(arguments, a, args[i+1])
+			i++
+		default:
+			arguments = append(arguments, absolutePathFlag(a, abs))
+		}
+	}
+
+	return arguments
+}
+
+// LanguageArguments returns args with an explicit "-x" for the language of the source file filename, e.g. "-x c++" for
+// "foo.cpp", so headers which are parsed with the arguments of filename get the same language. args is returned if it
+// already selects a language or the language of filename is unknown.
+func LanguageArguments(filename string, args []string) []string {
+	lang, ok := sourceLanguages[filepath.Ext(filename)]
+	if !ok || slices.Contains(args, "-x") {
+		return args
+	}
+
+	return append([]string{"-x", lang}, args...)
+}
+
+// absolutePathFlag returns a with an absolute path if it is a path flag joined with its path. Paths relative to the
+// sysroot, e.g. "-I=/usr/include", are not changed.
+func absolutePathFlag(a string, abs func(path string) string) string {
+	for _, f := range pathFlags {
+		if strings.HasPrefix(f, "--") {
+			f += "="
+		}
+
+		if path := strings.TrimPrefix(a, f); path != a && path != "" && !strings.HasPrefix(path, "=") && !strings.HasPrefix(path, "-") {
+			return f + abs(path)
+		}
+	}
+
+	return a
+}
+
+// compilationDatabase provides the Clang arguments of header files from a compilation database.
+type compilationDatabase struct {
+	db clang.CompilationDatabase
+
+	// representative holds the arguments of the first command of the database together with the language of its source
+	// file, which are used for headers without their own command
+	representative []string
+}
+
+// openCompilationDatabase opens the compilation database of the build directory dir.
+func openCompilationDatabase(dir string) (*compilationDatabase, error) {
+	cerr, db := clang.FromDirectory(dir)
+	if cerr != clang.CompilationDatabase_NoError {
+		return nil, fmt.Errorf("cannot load compilation database of %q: %w", dir, cerr)
+	}
+
+	cd := &compilationDatabase{
+		db: db,
+	}
+
+	cmds := db.AllCompileCommands()
+	defer cmds.Dispose()
+
+	if cmds.Size() > 0 {
+		cmd := cmds.Command(0)
+		cd.representative = LanguageArguments(cmd.Filename(), compileCommandArguments(cmd))
+	}
+
+	return cd, nil
+}
+
+// compileCommandArguments returns the Clang arguments of cmd.
+func compileCommandArguments(cmd clang.CompileCommand) []string {
+	args := make([]string, cmd.NumArgs())
+	for i := range args {
+		args[i] = cmd.Arg(uint32(i))
+	}
+
+	return CompileCommandArguments(cmd.Directory(), cmd.Filename(), args)
+}
+
+// Arguments returns the Clang arguments of the header file path, which are the ones of its own command if the database
+// has one, otherwise the ones of the representative command.
+func (cd *compilationDatabase) Arguments(path string) ([]string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := cd.db.CompileCommands(path)
+	defer cmds.Dispose()
+
+	if cmds.Size() > 0 {
+		return compileCommandArguments(cmds.Command(0)), nil
+	}
+
+	return cd.representative, nil
+}
+
+// Dispose releases the compilation database.
+func (cd *compilationDatabase) Dispose() {
+	cd.db.Dispose()
+}
@@ -0,0 +1,133 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestCompileCommandArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		directory string
+		filename  string
+		args      []string
+		want      []string
+	}{
+		"compile and output": {
+			directory: "/src/build",
+			filename:  "../lib/foo.c",
+			args:      []string{"/usr/bin/cc", "-DFOO=1", "-c", "../lib/foo.c", "-o", "foo.o"},
+			want:      []string{"-DFOO=1"},
+		},
+		"joined output": {
+			directory: "/src/build",
+			filename:  "/src/lib/foo.c",
+			args:      []string{"cc", "-ofoo.o", "-fobjc-arc", "-objcmt-migrate-literals", "/src/lib/foo.c"},
+			want:      []string{"-fobjc-arc", "-objcmt-migrate-literals"},
+		},
+		"relative include directories": {
+			directory: "/src/build",
+			filename:  "foo.c",
+			args:      []string{"cc", "-Iinclude", "-I", "../third_party", "-isystem", "/usr/local/include", "-iquote../quote", "-I=/usr/include", "foo.c"},
+			want:      []string{"-I/src/build/include", "-I", "/src/third_party", "-isystem", "/usr/local/include", "-iquote/src/quote", "-I=/usr/include"},
+		},
+		"relative included files, sysroots and frameworks": {
+			directory: "/src/build",
+			filename:  "foo.c",
+			args: []string{
+				"cc", "-include", "config.h", "-includeprefix.h", "-include-pch", "foo.pch",
+				"-isysroot", "sdk", "-isysroot../sdk", "--sysroot", "sysroot", "--sysroot=../sysroot",
+				"-F", "Frameworks", "-F../Frameworks", "foo.c",
+			},
+			want: []string{
+				"-include", "/src/build/config.h", "-include/src/build/prefix.h", "-include-pch", "/src/build/foo.pch",
+				"-isysroot", "/src/build/sdk", "-isysroot/src/sdk", "--sysroot", "/src/build/sysroot", "--sysroot=/src/sysroot",
+				"-F", "/src/build/Frameworks", "-F/src/Frameworks",
+			},
+		},
+		"relative source file": {
+			directory: "/src",
+			filename:  "/src/lib/foo.c",
+			args:      []string{"cc", "-x", "c", "lib/foo.c"},
+			want:      []string{"-x", "c"},
+		},
+		"positional values": {
+			directory: "/src",
+			filename:  "foo.m",
+			args:      []string{"cc", "-framework", "Foundation", "-mllvm", "-enable-misched", "foo.m"},
+			want:      []string{"-framework", "Foundation", "-mllvm", "-enable-misched"},
+		},
+		"separate values": {
+			directory: "/src",
+			filename:  "foo.c",
+			args:      []string{"cc", "-D", "FOO", "-U", "BAR", "-target", "x86_64-linux-gnu", "-Xclang", "-fno-validate-pch", "foo.c"},
+			want:      []string{"-D", "FOO", "-U", "BAR", "-target", "x86_64-linux-gnu", "-Xclang", "-fno-validate-pch"},
+		},
+		"compiler only": {
+			directory: "/src",
+			args:      []string{"cc"},
+			want:      nil,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := gen.CompileCommandArguments(tt.directory, tt.filename, tt.args)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("CompileCommandArguments(%q, %q, %q): (-want +got):\n%s", tt.directory, tt.filename, tt.args, diff)
+			}
+		})
+	}
+}
+
+func TestLanguageArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		filename string
+		args     []string
+		want     []string
+	}{
+		"c": {
+			filename: "/src/foo.c",
+			args:     []string{"-DFOO"},
+			want:     []string{"-x", "c", "-DFOO"},
+		},
+		"c++": {
+			filename: "/src/foo.cpp",
+			args:     []string{"-std=c++17"},
+			want:     []string{"-x", "c++", "-std=c++17"},
+		},
+		"objective-c++": {
+			filename: "foo.mm",
+			want:     []string{"-x", "objective-c++"},
+		},
+		"language already selected": {
+			filename: "foo.c",
+			args:     []string{"-x", "c++"},
+			want:     []string{"-x", "c++"},
+		},
+		"unknown language": {
+			filename: "foo.inc",
+			args:     []string{"-DFOO"},
+			want:     []string{"-DFOO"},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := gen.LanguageArguments(tt.filename, tt.args)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("LanguageArguments(%q, %q): (-want +got):\n%s", tt.filename, tt.args, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// NameConflictError is returned if different C functions map to the same Go function or method, or if a C function
+// maps to the Go name of a generated declaration.
+type NameConflictError struct {
+	// Receiver holds the name of the receiver type or is empty for functions.
+	Receiver string
+	Name     string
+	CNames   []string
+	// Declarations holds the generated declarations with the same name which are no C functions, e.g. "method IsNull".
+	Declarations []string
+}
+
+// Error returns the error message of e.
+func (e *NameConflictError) Error() string {
+	name := e.Name
+	if e.Receiver != "" {
+		name = e.Receiver + "." + e.Name
+	}
+
+	switch {
+	case len(e.Declarations) == 0:
+		return fmt.Sprintf("C functions %s map to the same Go name %s", strings.Join(e.CNames, ", "), name)
+
+	case len(e.CNames) == 0:
+		return fmt.Sprintf("generated %s have the same Go name %s", strings.Join(e.Declarations, ", "), name)
+	}
+
+	return fmt.Sprintf("C functions %s map to the Go name %s of the generated %s", strings.Join(e.CNames, ", "), name, strings.Join(e.Declarations, ", "))
+}
+
+// declarations returns the top-level declarations of the Go source src, which has no package clause, as mapping from
+// their names to their kind and name, e.g. "method IsNull".
+func declarations(src string) (map[string]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package clang\n\n"+src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	decls := map[string]string{}
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			kind := "function"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			decls[d.Name.Name] = kind + " " + d.Name.Name
+
+		case *ast.GenDecl:
+			for _, s := range d.Specs {
+				switch s := s.(type) {
+				case *ast.TypeSpec:
+					decls[s.Name.Name] = "type " + s.Name.Name
+
+				case *ast.ValueSpec:
+					kind := "variable"
+					if d.Tok == token.CONST {
+						kind = "constant"
+					}
+					for _, n := range s.Names {
+						decls[n.Name] = kind + " " + n.Name
+					}
+				}
+			}
+		}
+	}
+
+	return decls, nil
+}
+
+// fixFunctionNames applies API.FixFunctionName to the functions of methods.
+func (g *Generation) fixFunctionNames(methods []interface{}) {
+	if g.api.FixFunctionName == nil {
+		return
+	}
+
+	for _, m := range methods {
+		if f, ok := m.(*Function); ok {
+			if fname := g.api.FixFunctionName(f); fname != "" {
+				f.Name = fname
+			}
+		}
+	}
+}
+
+// packageDeclarations returns the enums, enum items and structs of g which are declared at the package level as
+// mapping from their names to their kind and name.
+func (g *Generation) packageDeclarations() map[string]string {
+	decls := map[string]string{}
+
+	for _, e := range g.enums {
+		decls[e.Name] = "type " + e.Name
+
+		for _, ei := range e.Items {
+			decls[ei.Name] = "constant " + ei.Name
+		}
+	}
+
+	for _, s := range g.structs {
+		decls[s.Name] = "type " + s.Name
+	}
+
+	return decls
+}
+
+// FunctionNameFromCName returns a Go name derived from the full C name of f.
+func FunctionNameFromCName(f *Function) string {
+	ns := strings.Split(strings.TrimPrefix(f.CName, "clang_"), "_")
+	for i := range ns {
+		if ns[i] != "" {
+			ns[i] = UpperFirstCharacter(ns[i])
+		}
+	}
+
+	return strings.Join(ns, "")
+}
+
+// ResolveNameConflicts checks that no two C functions of methods map to the same Go name of receiver, which is empty
+// for functions, and that no C function maps to the name of a generated declaration. Generated declarations are the
+// methods of methods which are Go source, and for functions also the enums, enum items and structs of the package. It
+// must therefore be called after all methods of receiver were added. Conflicts are renamed if API.ResolveNameConflicts
+// is set, otherwise a *NameConflictError is returned.
+//
+// Conflicting functions are ordered by their C name length and C name. The first function keeps its name unless the
+// name is taken by a generated declaration, every other function is renamed to a name derived from its full C name.
+// Struct field getters and generated declarations are never renamed.
+func (g *Generation) ResolveNameConflicts(receiver string, methods []interface{}) error {
+	declared := map[string]string{}
+	if receiver == "" {
+		declared = g.packageDeclarations()
+	}
+
+	names := map[string][]*Function{}
+	for _, m := range methods {
+		switch m := m.(type) {
+		case *Function:
+			names[m.Name] = append(names[m.Name], m)
+
+		case string:
+			decls, err := declarations(m)
+			if err != nil {
+				return fmt.Errorf("cannot parse generated declaration of %q: %w", receiver, err)
+			}
+
+			for name, decl := range decls {
+				if other, ok := declared[name]; ok {
+					return &NameConflictError{
+						Receiver:     receiver,
+						Name:         name,
+						Declarations: []string{other, decl},
+					}
+				}
+
+				declared[name] = decl
+			}
+		}
+	}
+
+	conflicts := make([]string, 0, len(names))
+	for name, fs := range names {
+		if _, ok := declared[name]; ok || len(fs) > 1 {
+			conflicts = append(conflicts, name)
+		}
+	}
+	sort.Strings(conflicts)
+
+	for _, name := range conflicts {
+		fs := names[name]
+
+		sort.SliceStable(fs, func(i, j int) bool {
+			if (fs[i].Member != nil) != (fs[j].Member != nil) {
+				return fs[i].Member != nil
+			}
+			if len(fs[i].CName) != len(fs[j].CName) {
+				return len(fs[i].CName) < len(fs[j].CName)
+			}
+
+			return fs[i].CName < fs[j].CName
+		})
+
+		cnames := make([]string, len(fs))
+		for i, f := range fs {
+			cnames[i] = f.CName
+		}
+
+		err := &NameConflictError{
+			Receiver: receiver,
+			Name:     name,
+			CNames:   cnames,
+		}
+
+		kept, renamed := fs[0].CName, fs[1:]
+		if decl, ok := declared[name]; ok {
+			kept, renamed = decl, fs
+			err.Declarations = []string{decl}
+		}
+
+		if !g.api.ResolveNameConflicts {
+			return err
+		}
+
+		for _, f := range renamed {
+			fname := FunctionNameFromCName(f)
+			if _, ok := names[fname]; ok || f.Member != nil {
+				return err
+			}
+			if _, ok := declared[fname]; ok {
+				return err
+			}
+
+			g.report.Add(ReportRenamed, f.CName, "renamed from %s to %s because it conflicts with %s", f.Name, fname, kept)
+
+			f.Name = fname
+			names[fname] = []*Function{f}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package gen_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestResolveNameConflicts(t *testing.T) {
+	t.Parallel()
+
+	isNull := `// IsNull reports whether the underlying C pointer is NULL.
+func (tu TranslationUnit) IsNull() bool {
+	return tu.c == nil
+}`
+
+	tests := map[string]struct {
+		resolve   bool
+		receiver  string
+		functions []*gen.Function
+		sources   []string
+		enums     []*gen.Enum
+		want      []string
+		wantErr   bool
+	}{
+		"no conflict": {
+			receiver: "TranslationUnit",
+			functions: []*gen.Function{
+				{Name: "Cursor", CName: "clang_getCursor"},
+				{Name: "TranslationUnitCursor", CName: "clang_getTranslationUnitCursor"},
+			},
+			want: []string{"Cursor", "TranslationUnitCursor"},
+		},
+		"conflict": {
+			receiver: "TranslationUnit",
+			functions: []*gen.Function{
+				{Name: "Cursor", CName: "clang_getTranslationUnitCursor"},
+				{Name: "Cursor", CName: "clang_getCursor"},
+			},
+			wantErr: true,
+		},
+		"resolved conflict": {
+			receiver: "TranslationUnit",
+			resolve:  true,
+			functions: []*gen.Function{
+				{Name: "Cursor", CName: "clang_getTranslationUnitCursor"},
+				{Name: "Cursor", CName: "clang_getCursor"},
+			},
+			want: []string{"GetTranslationUnitCursor", "Cursor"},
+		},
+		"conflict with generated method": {
+			receiver: "TranslationUnit",
+			functions: []*gen.Function{
+				{Name: "IsNull", CName: "clang_TranslationUnit_isNull"},
+			},
+			sources: []string{isNull},
+			wantErr: true,
+		},
+		"resolved conflict with generated method": {
+			receiver: "TranslationUnit",
+			resolve:  true,
+			functions: []*gen.Function{
+				{Name: "IsNull", CName: "clang_TranslationUnit_isNull"},
+				{Name: "Cursor", CName: "clang_getTranslationUnitCursor"},
+			},
+			sources: []string{isNull},
+			want:    []string{"TranslationUnitIsNull", "Cursor"},
+		},
+		"conflicting generated methods": {
+			receiver: "TranslationUnit",
+			resolve:  true,
+			sources:  []string{isNull, isNull},
+			wantErr:  true,
+		},
+		"conflict with package type": {
+			functions: []*gen.Function{
+				{Name: "CursorKind", CName: "clang_getCursorKind"},
+			},
+			enums:   []*gen.Enum{{Name: "CursorKind", Items: []gen.EnumItem{{Name: "Cursor_StructDecl"}}}},
+			wantErr: true,
+		},
+		"resolved conflict with package constant": {
+			resolve: true,
+			functions: []*gen.Function{
+				{Name: "Cursor_StructDecl", CName: "clang_Cursor_StructDecl"},
+			},
+			enums: []*gen.Enum{{Name: "CursorKind", Items: []gen.EnumItem{{Name: "Cursor_StructDecl"}}}},
+			want:  []string{"CursorStructDecl"},
+		},
+		"generated constant": {
+			functions: []*gen.Function{
+				{Name: "Cursor", CName: "clang_getCursor"},
+			},
+			sources: []string{"// CINDEX_VERSION is the version of the headers.\nconst CINDEX_VERSION = 62"},
+			want:    []string{"Cursor"},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			api := &gen.API{
+				ResolveNameConflicts: tt.resolve,
+			}
+
+			h := gen.NewHeaderFile(api, "Index.h", "testdata")
+			h.Enums = tt.enums
+
+			g := gen.NewGeneration(api)
+			g.AddHeaderFiles([]*gen.HeaderFile{h})
+
+			methods := make([]interface{}, 0, len(tt.functions)+len(tt.sources))
+			for _, f := range tt.functions {
+				methods = append(methods, f)
+			}
+			for _, src := range tt.sources {
+				methods = append(methods, src)
+			}
+
+			err := g.ResolveNameConflicts(tt.receiver, methods)
+			if tt.wantErr {
+				var nce *gen.NameConflictError
+				if !errors.As(err, &nce) {
+					t.Fatalf("ResolveNameConflicts() error = %v, want *NameConflictError", err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveNameConflicts() error = %v", err)
+			}
+
+			got := make([]string, len(tt.functions))
+			for i, f := range tt.functions {
+				got[i] = f.Name
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("ResolveNameConflicts(): (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGeneration_GenerateMethodKeepsResolvedName(t *testing.T) {
+	t.Parallel()
+
+	g := gen.NewGeneration(&gen.API{
+		FixFunctionName: func(f *gen.Function) string {
+			if f.CName == "clang_getTranslationUnitCursor" {
+				return "Cursor"
+			}
+
+			return ""
+		},
+	})
+
+	f := &gen.Function{
+		Name:  "GetTranslationUnitCursor",
+		CName: "clang_getTranslationUnitCursor",
+		Parameters: []gen.FunctionParameter{
+			{Name: "tu", CName: "tu", Type: gen.Type{CName: "CXTranslationUnit", CGoName: "CXTranslationUnit", GoName: "TranslationUnit"}},
+		},
+		ReturnType: gen.Type{CName: "CXCursor", CGoName: "CXCursor", GoName: "Cursor"},
+	}
+
+	src, err := g.GenerateMethod("TranslationUnit", f)
+	if err != nil {
+		t.Fatalf("GenerateMethod() error = %v", err)
+	}
+	if f.Name != "GetTranslationUnitCursor" || !strings.Contains(src, ") GetTranslationUnitCursor()") {
+		t.Fatalf("GenerateMethod() renamed %s to %s:\n%s", "GetTranslationUnitCursor", f.Name, src)
+	}
+}
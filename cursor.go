@@ -0,0 +1,23 @@
+package gen
+
+import (
+	"iter"
+
+	"github.com/go-clang/bootstrap/clang"
+)
+
+// cursorChildren returns an iterator over the direct children of c.
+//
+// The bootstrap bindings only offer the callback based clang.Cursor.Visit, this mirrors the Cursor.Children method of
+// the generated bindings.
+func cursorChildren(c clang.Cursor) iter.Seq[clang.Cursor] {
+	return func(yield func(clang.Cursor) bool) {
+		c.Visit(func(cursor, _ clang.Cursor) clang.ChildVisitResult {
+			if !yield(cursor) {
+				return clang.ChildVisit_Break
+			}
+
+			return clang.ChildVisit_Continue
+		})
+	}
+}
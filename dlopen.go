@@ -0,0 +1,308 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// dlopenType describes how a Type is passed through a dlopen trampoline.
+type dlopenType struct {
+	// GoType is the type used by the Go wrapper.
+	GoType string
+	// CType is the type used by the trampoline.
+	CType string
+	// ToC converts a GoType value into a CType value as fmt pattern.
+	ToC string
+	// FromC converts a CType value into a GoType value as fmt pattern.
+	FromC string
+}
+
+// dlopenCTypes maps primitive cgo type names to their fixed size Go equivalents.
+var dlopenCTypes = map[string]string{
+	CSChar:     GoInt8,
+	CUChar:     GoUInt8,
+	CShort:     GoInt16,
+	CUShort:    GoUInt16,
+	CInt:       GoInt32,
+	CUInt:      GoUInt32,
+	CLongInt:   GoInt64,
+	CULongInt:  GoUInt64,
+	CLongLong:  GoInt64,
+	CULongLong: GoUInt64,
+	CFloat:     GoFloat32,
+	CDouble:    GoFloat64,
+}
+
+// dlopenFunction represents a function which is bound by a dlopen trampoline.
+type dlopenFunction struct {
+	Comment string
+	CName   string
+	Source  string
+
+	TrampolineParameters []string
+	TrampolineResult     string
+}
+
+// dlopenTypeOf returns how typ is passed through a dlopen trampoline and reports whether this is supported.
+func (g *Generation) dlopenTypeOf(typ Type) (dlopenType, bool) {
+	if typ.IsSlice || typ.IsArray || typ.IsReturnArgument || typ.IsFunctionPointer || typ.LengthOfSlice != "" {
+		return dlopenType{}, false
+	}
+
+	if typ.PointerLevel == 1 && typ.CGoName == CSChar {
+		return dlopenType{GoType: "string", CType: "string", ToC: "%s", FromC: "%s"}, true
+	}
+
+	if typ.PointerLevel != 0 {
+		return dlopenType{}, false
+	}
+
+	if e, ok := g.HasEnum(typ.GoName); ok {
+		return dlopenType{GoType: e.Name, CType: e.Name, ToC: "%s", FromC: "%s"}, true
+	}
+
+	if s, ok := g.HasStruct(typ.GoName); ok {
+		if !s.IsPointerTypedef {
+			return dlopenType{}, false
+		}
+
+		return dlopenType{GoType: s.Name, CType: "uintptr", ToC: "%s.c", FromC: s.Name + "{%s}"}, true
+	}
+
+	switch typ.GoName {
+	case GoBool:
+		ct, ok := dlopenCTypes[typ.CGoName]
+		if !ok {
+			return dlopenType{}, false
+		}
+
+		return dlopenType{GoType: GoBool, CType: ct, FromC: "%s != 0"}, true
+
+	case "time.Time":
+		return dlopenType{GoType: "time.Time", CType: GoInt64, ToC: "%s.Unix()", FromC: "time.Unix(%s, 0)"}, true
+	}
+
+	ct, ok := dlopenCTypes[typ.CGoName]
+	if !ok {
+		return dlopenType{}, false
+	}
+
+	dt := dlopenType{GoType: typ.GoName, CType: ct, ToC: "%s", FromC: "%s"}
+	if typ.GoName != ct {
+		dt.ToC = ct + "(%s)"
+		dt.FromC = typ.GoName + "(%s)"
+	}
+
+	return dt, true
+}
+
+// addDlopenFunction adds f to the functions which are bound by dlopen trampolines.
+func (g *Generation) addDlopenFunction(f *Function) error {
+	df := dlopenFunction{
+		Comment: f.Comment,
+		CName:   f.CName,
+	}
+
+	var receiver string
+	var parameters []string
+	var arguments []string
+
+	for i, p := range f.Parameters {
+		dt, ok := g.dlopenTypeOf(p.Type)
+		if !ok || dt.ToC == "" {
+			return fmt.Errorf("parameter %q of type %q is not supported", p.CName, p.Type.CName)
+		}
+
+		name := p.Name
+		if i == 0 && f.Receiver.Name != "" {
+			name = f.Receiver.Name
+			receiver = name + " " + dt.GoType
+		} else {
+			parameters = append(parameters, name+" "+dt.GoType)
+		}
+
+		df.TrampolineParameters = append(df.TrampolineParameters, dt.CType)
+		arguments = append(arguments, fmt.Sprintf(dt.ToC, name))
+	}
+
+	call := f.CName + "(" + strings.Join(arguments, ", ") + ")"
+
+	var result string
+	var body string
+
+	if f.ReturnType.GoName == "void" && f.ReturnType.PointerLevel == 0 {
+		body = call
+	} else {
+		dt, ok := g.dlopenTypeOf(f.ReturnType)
+		if !ok {
+			return fmt.Errorf("return type %q is not supported", f.ReturnType.CName)
+		}
+
+		df.TrampolineResult = dt.CType
+		result = dt.GoType
+		body = "return " + fmt.Sprintf(dt.FromC, call)
+
+		if f.ReturnType.IsNullable {
+			result = "(" + dt.GoType + ", bool)"
+			body = "o := " + fmt.Sprintf(dt.FromC, call) + "\n\n\treturn o, o.c != 0"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("func ")
+	if receiver != "" {
+		b.WriteString("(" + receiver + ") ")
+	}
+	b.WriteString(f.Name + "(" + strings.Join(parameters, ", ") + ") " + result + " {\n\t" + body + "\n}")
+	df.Source = b.String()
+
+	g.dlopenFunctions = append(g.dlopenFunctions, df)
+
+	return nil
+}
+
+var templateGenerateDlopenFile = template.Must(template.New("go-clang-generate-dlopen-file").Parse(`package clang
+
+import (
+	"fmt"
+
+	"github.com/ebitengine/purego"
+)
+
+// lib holds the handle of the libclang shared library which was loaded by Load.
+var lib uintptr
+
+// Load loads the libclang shared library at path and binds all functions of this package to it.
+{{if $.ABIGuard}}// It fails if the library is older than the headers the bindings were generated from, see CheckABI.
+{{end}}//
+// Load must be called before any other function of this package is used.
+func Load(path string) error {
+	l, err := purego.Dlopen(path, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		return fmt.Errorf("cannot load %s: %w", path, err)
+	}
+
+	for _, t := range trampolines {
+		if _, err := purego.Dlsym(l, t.name); err != nil {
+			return fmt.Errorf("cannot find symbol %s: %w", t.name, err)
+		}
+
+		purego.RegisterLibFunc(t.fptr, l, t.name)
+	}
+
+	lib = l
+{{if $.ABIGuard}}
+	return CheckABI()
+}
+
+// CheckABI checks that the libclang loaded by Load is not older than the headers the bindings were generated from, i.e.
+// that it defines every function of the bindings and that its LLVM version is not older.
+func CheckABI() error {
+	if lib == 0 {
+		return errors.New("libclang is not loaded")
+	}
+
+	if err := checkCIndexSymbols(func(symbol string) bool {
+		_, err := purego.Dlsym(lib, symbol)
+
+		return err == nil
+	}); err != nil {
+		return err
+	}
+
+	v, err := clangVersion()
+	if err != nil {
+		return err
+	}
+
+	return checkClangVersion(v)
+}
+
+// clangVersion returns clang_getClangVersion of the loaded libclang. The returned CXString is a struct of two words
+// which is returned and passed in two registers by the calling conventions supported by purego.Dlopen.
+func clangVersion() (string, error) {
+	getClangVersion, err := purego.Dlsym(lib, "clang_getClangVersion")
+	if err != nil {
+		return "", fmt.Errorf("cannot find symbol clang_getClangVersion: %w", err)
+	}
+
+	var getCString func(data uintptr, flags uintptr) string
+	purego.RegisterLibFunc(&getCString, lib, "clang_getCString")
+	var disposeString func(data uintptr, flags uintptr)
+	purego.RegisterLibFunc(&disposeString, lib, "clang_disposeString")
+
+	data, flags, _ := purego.SyscallN(getClangVersion)
+	defer disposeString(data, flags)
+
+	return getCString(data, flags), nil
+}
+{{else}}
+	return nil
+}
+{{end}}
+var trampolines = []struct {
+	fptr interface{}
+	name string
+}{
+{{range $f := $.Functions}}	{&{{$f.CName}}, "{{$f.CName}}"},
+{{end}}}
+
+var (
+{{range $f := $.Functions}}	{{$f.CName}} func({{range $i, $p := $f.TrampolineParameters}}{{if $i}}, {{end}}{{$p}}{{end}}) {{$f.TrampolineResult}}
+{{end}})
+
+{{range $e := $.Enums}}
+{{$e.Comment}}
+type {{$e.Name}} {{$e.UnderlyingType}}
+
+const (
+{{range $ei := $e.Items}}	{{if $ei.Comment}}{{$ei.Comment}}
+	{{end}}{{$ei.Name}} {{$e.Name}} = {{$e.ItemValue $ei}}
+{{end}})
+{{end}}
+
+{{range $s := $.Structs}}
+{{$s.Comment}}
+type {{$s.Name}} struct {
+	c uintptr
+}
+{{end}}
+
+{{range $f := $.Functions}}
+{{$f.Comment}}
+{{$f.Source}}
+{{end}}
+`))
+
+// GenerateDlopen generates the bindings which load libclang at runtime via dlopen instead of linking it through cgo.
+//
+// The generated file is only built without cgo and depends on github.com/ebitengine/purego. It contains all enums, all
+// opaque handle structs and wrappers for every function whose signature can be passed through a trampoline. If the ABI
+// guard is generated, it also contains the CheckABI function of builds without cgo, which is called by Load.
+func (g *Generation) GenerateDlopen() error {
+	var structs []*Struct
+	for _, s := range g.structs {
+		if s.IsPointerTypedef {
+			structs = append(structs, s)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := templateGenerateDlopenFile.Execute(&b, struct {
+		Enums     []*Enum
+		Structs   []*Struct
+		Functions []dlopenFunction
+		ABIGuard  bool
+	}{
+		Enums:     g.enums,
+		Structs:   structs,
+		Functions: g.dlopenFunctions,
+		ABIGuard:  g.hasABIGuard(),
+	}); err != nil {
+		return err
+	}
+
+	return writeGoFile(g.constrainedOutput("!cgo"), "dlopen_gen.go", b.Bytes())
+}
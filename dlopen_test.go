@@ -0,0 +1,212 @@
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+// newDlopenGeneration returns a generation of the dlopen backend with the enum CursorKind, the pointer struct Index
+// and the value struct Cursor.
+func newDlopenGeneration(api *gen.API, dir string) *gen.Generation {
+	api.Backend = gen.BackendDlopen
+
+	h := gen.NewHeaderFile(api, "Index.h", dir)
+	h.Enums = []*gen.Enum{
+		{
+			Name:           "CursorKind",
+			CName:          "CXCursorKind",
+			Comment:        "// Describes the kind of entity that a cursor refers to.",
+			UnderlyingType: gen.GoUInt32,
+			Items: []gen.EnumItem{
+				{Name: "Cursor_UnexposedDecl", CName: "CXCursor_UnexposedDecl", Value: 1},
+				{Name: "Cursor_StructDecl", CName: "CXCursor_StructDecl", Value: 2},
+			},
+		},
+	}
+	h.Structs = []*gen.Struct{
+		{Name: "Index", CName: "CXIndex", Comment: "// An index.", IsPointerTypedef: true},
+		{Name: "Cursor", CName: "CXCursor"},
+	}
+
+	g := gen.NewGeneration(api)
+	g.AddHeaderFiles([]*gen.HeaderFile{h})
+
+	return g
+}
+
+func TestGeneration_DlopenTypeOf(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		typ    gen.Type
+		want   gen.DlopenType
+		wantOK bool
+	}{
+		"int": {
+			typ:    gen.Type{CGoName: gen.CInt, GoName: gen.GoInt32},
+			want:   gen.DlopenType{GoType: gen.GoInt32, CType: gen.GoInt32, ToC: "%s", FromC: "%s"},
+			wantOK: true,
+		},
+		"converted": {
+			typ:    gen.Type{CGoName: gen.CULongLong, GoName: "SizeT"},
+			want:   gen.DlopenType{GoType: "SizeT", CType: gen.GoUInt64, ToC: "uint64(%s)", FromC: "SizeT(%s)"},
+			wantOK: true,
+		},
+		"bool": {
+			typ:    gen.Type{CGoName: gen.CUInt, GoName: gen.GoBool},
+			want:   gen.DlopenType{GoType: gen.GoBool, CType: gen.GoUInt32, FromC: "%s != 0"},
+			wantOK: true,
+		},
+		"string": {
+			typ:    gen.Type{CGoName: gen.CSChar, GoName: gen.GoInt8, PointerLevel: 1},
+			want:   gen.DlopenType{GoType: "string", CType: "string", ToC: "%s", FromC: "%s"},
+			wantOK: true,
+		},
+		"time": {
+			typ:    gen.Type{CGoName: gen.CLongInt, GoName: "time.Time"},
+			want:   gen.DlopenType{GoType: "time.Time", CType: gen.GoInt64, ToC: "%s.Unix()", FromC: "time.Unix(%s, 0)"},
+			wantOK: true,
+		},
+		"enum": {
+			typ:    gen.Type{CGoName: "enum_CXCursorKind", GoName: "CursorKind"},
+			want:   gen.DlopenType{GoType: "CursorKind", CType: "CursorKind", ToC: "%s", FromC: "%s"},
+			wantOK: true,
+		},
+		"pointer struct": {
+			typ:    gen.Type{CGoName: "CXIndex", GoName: "Index"},
+			want:   gen.DlopenType{GoType: "Index", CType: "uintptr", ToC: "%s.c", FromC: "Index{%s}"},
+			wantOK: true,
+		},
+		"value struct": {
+			typ: gen.Type{CGoName: "CXCursor", GoName: "Cursor"},
+		},
+		"pointer": {
+			typ: gen.Type{CGoName: gen.CInt, GoName: gen.GoInt32, PointerLevel: 1},
+		},
+		"slice": {
+			typ: gen.Type{CGoName: gen.CInt, GoName: gen.GoInt32, IsSlice: true},
+		},
+		"unknown": {
+			typ: gen.Type{CGoName: "CXString", GoName: "cxstring"},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g := newDlopenGeneration(&gen.API{}, "testdata/dlopen")
+
+			got, ok := g.DlopenTypeOf(tt.typ)
+			if ok != tt.wantOK {
+				t.Fatalf("DlopenTypeOf(%+v) ok = %t, want %t", tt.typ, ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("DlopenTypeOf(%+v): (-want +got):\n%s", tt.typ, diff)
+			}
+		})
+	}
+}
+
+func TestGeneration_GenerateDlopen(t *testing.T) {
+	t.Parallel()
+
+	index := gen.Type{CName: "CXIndex", CGoName: "CXIndex", GoName: "Index"}
+
+	functions := []*gen.Function{
+		{
+			Name:    "NewIndex",
+			CName:   "clang_createIndex",
+			Comment: "// Provides a shared context for creating translation units.",
+			Parameters: []gen.FunctionParameter{
+				{Name: "excludeDeclarationsFromPCH", Type: gen.Type{CGoName: gen.CInt, GoName: gen.GoInt32}},
+				{Name: "displayDiagnostics", Type: gen.Type{CGoName: gen.CInt, GoName: gen.GoInt32}},
+			},
+			ReturnType: index,
+		},
+		{
+			Name:       "Dispose",
+			CName:      "clang_disposeIndex",
+			Parameters: []gen.FunctionParameter{{Name: "idx", Type: index}},
+			ReturnType: gen.Type{GoName: "void"},
+			Receiver:   gen.Receiver{Name: "i", Type: index},
+		},
+		{
+			Name:       "IsDeclaration",
+			CName:      "clang_isDeclaration",
+			Parameters: []gen.FunctionParameter{{Name: "ck", Type: gen.Type{CGoName: "enum_CXCursorKind", GoName: "CursorKind"}}},
+			ReturnType: gen.Type{CGoName: gen.CUInt, GoName: gen.GoBool},
+			Receiver:   gen.Receiver{Name: "ck", Type: gen.Type{GoName: "CursorKind"}},
+		},
+	}
+
+	tests := map[string]struct {
+		api  gen.API
+		dir  string
+		want string
+	}{
+		"without ABI guard": {
+			dir:  "testdata/dlopen",
+			want: "dlopen/dlopen_gen.go",
+		},
+		"with ABI guard": {
+			api:  gen.API{LLVMVersion: "15.0.7"},
+			dir:  "testdata/abi",
+			want: "dlopen/dlopen_abi_gen.go",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := gen.NewMemoryOutput()
+			tt.api.Output = out
+			g := newDlopenGeneration(&tt.api, tt.dir)
+
+			for _, f := range functions {
+				if err := g.AddDlopenFunction(f); err != nil {
+					t.Fatalf("AddDlopenFunction(%s) error = %v", f.CName, err)
+				}
+			}
+			if err := g.AddDlopenFunction(&gen.Function{
+				Name:       "Spelling",
+				CName:      "clang_getCursorKindSpelling",
+				Parameters: []gen.FunctionParameter{{Name: "kind", Type: gen.Type{CGoName: "enum_CXCursorKind", GoName: "CursorKind"}}},
+				ReturnType: gen.Type{CGoName: "CXString", GoName: "cxstring"},
+			}); err == nil {
+				t.Fatal("AddDlopenFunction(clang_getCursorKindSpelling) error = nil, want unsupported return type")
+			}
+
+			if err := g.GenerateDlopen(); err != nil {
+				t.Fatalf("GenerateDlopen() error = %v", err)
+			}
+
+			checkGolden(t, tt.want, out.Files()["dlopen_gen.go"])
+		})
+	}
+}
+
+func TestGeneration_GenerateDlopenHeader(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := gen.NewHeaderTemplate("// Copyright 2026 The Authors.\n\n//go:build linux{{if $.Constraint}} && ({{$.Constraint}}){{end}}")
+	if err != nil {
+		t.Fatalf("NewHeaderTemplate() error = %v", err)
+	}
+
+	out := gen.NewMemoryOutput()
+	g := newDlopenGeneration(&gen.API{Output: out, HeaderTemplate: tmpl}, "testdata/dlopen")
+	if err := g.GenerateDlopen(); err != nil {
+		t.Fatalf("GenerateDlopen() error = %v", err)
+	}
+
+	want := "// Copyright 2026 The Authors.\n\n//go:build linux && (!cgo)\n\npackage clang\n"
+	if got := string(out.Files()["dlopen_gen.go"]); !strings.HasPrefix(got, want) {
+		t.Fatalf("GenerateDlopen(): want prefix %q but got %q", want, got)
+	}
+}
@@ -1,9 +1,10 @@
 package gen
 
 import (
-	"fmt"
 	"go/ast"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/go-clang/bootstrap/clang"
 )
@@ -18,6 +19,8 @@ type Enum struct {
 	Receiver       Receiver
 	Comment        string
 	UnderlyingType string
+	// IsSigned reports whether the underlying type is a signed integer type.
+	IsSigned bool
 
 	Items []EnumItem
 
@@ -29,11 +32,14 @@ type EnumItem struct {
 	Name    string
 	CName   string
 	Comment string
-	Value   uint64
+	// Value holds the bit pattern of the item value, see Enum.ItemValue for its Go literal.
+	Value uint64
+	// Since holds the LLVM version which introduced the item, if known.
+	Since string
 }
 
 // HandleEnumCursor handles enum clang.Cursor and roterns the new *Enum.
-func HandleEnumCursor(cursor clang.Cursor, cname string, cnameIsTypeDef bool) *Enum {
+func HandleEnumCursor(cursor clang.Cursor, cname string, cnameIsTypeDef bool) (*Enum, error) {
 	e := Enum{
 		IncludeFiles:   NewIncludeFiles(),
 		Name:           TrimLanguagePrefix(cname),
@@ -52,16 +58,29 @@ func HandleEnumCursor(cursor clang.Cursor, cname string, cnameIsTypeDef bool) *E
 		e.Receiver.Type.CGoName = "enum_" + e.CName
 	}
 
+	if typ, err := TypeFromClangType(cursor.EnumDeclIntegerType().CanonicalType()); err == nil && IsInteger(&typ) {
+		e.UnderlyingType = typ.GoName
+	} else if strings.HasSuffix(e.Name, "Error") {
+		e.UnderlyingType = GoInt32
+	} else {
+		e.UnderlyingType = GoUInt32
+	}
+	e.IsSigned = strings.HasPrefix(e.UnderlyingType, "int")
+
 	enumNamePrefix := e.Name
 	enumNamePrefix = strings.TrimSuffix(enumNamePrefix, "Kind")
 	enumNamePrefix = strings.SplitN(enumNamePrefix, "_", 2)[0]
 
-	cursor.Visit(func(cursor, parent clang.Cursor) clang.ChildVisitResult {
+	for cursor := range cursorChildren(cursor) {
 		switch cursor.Kind() {
 		case clang.Cursor_EnumConstantDecl:
 			ei := EnumItem{
 				CName: cursor.Spelling(),
-				Value: cursor.EnumConstantDeclUnsignedValue(),
+			}
+			if e.IsSigned {
+				ei.Value = uint64(cursor.EnumConstantDeclValue())
+			} else {
+				ei.Value = cursor.EnumConstantDeclUnsignedValue()
 			}
 			ei.Name = TrimLanguagePrefix(ei.CName)
 			// TODO(go-clang): we are always using the same comment if there is none, see "TypeKind"
@@ -87,19 +106,20 @@ func HandleEnumCursor(cursor clang.Cursor, cname string, cnameIsTypeDef bool) *E
 			e.Items = append(e.Items, ei)
 
 		default:
-			panic(fmt.Errorf("unexpected cursor.Kind: %#v", cursor.Kind()))
+			return nil, newCursorError(cursor, cname, "unexpected cursor kind %s in enum", cursor.Kind().Spelling())
 		}
+	}
 
-		return clang.ChildVisit_Continue
-	})
+	return &e, nil
+}
 
-	if strings.HasSuffix(e.Name, "Error") {
-		e.UnderlyingType = "int32"
-	} else {
-		e.UnderlyingType = "uint32"
+// ItemValue returns the value of ei as Go integer literal respecting the signedness of e.
+func (e *Enum) ItemValue(ei EnumItem) string {
+	if e.IsSigned {
+		return strconv.FormatInt(int64(ei.Value), 10)
 	}
 
-	return &e
+	return strconv.FormatUint(ei.Value, 10)
 }
 
 // ContainsMethod reports whether the contains name to Enum.Methods.
@@ -121,12 +141,18 @@ func (e *Enum) ContainsMethod(name string) bool {
 	return false
 }
 
-// Generate generates enum.
-func (e *Enum) Generate() error {
+// File returns the generation file of enum.
+func (e *Enum) File() *File {
 	f := NewFile(strings.ToLower(e.Name))
 	f.Enums = append(f.Enums, e)
 
-	return f.Generate()
+	return f
+}
+
+// Generate generates enum with the file template tmpl and writes it to out. DefaultFileTemplate is used if tmpl is
+// nil.
+func (e *Enum) Generate(out Output, tmpl *template.Template) error {
+	return e.File().Generate(out, tmpl)
 }
 
 // AddEnumStringMethods adds Enum String methods to e.
@@ -204,7 +230,11 @@ func (e *Enum) AddEnumSpellingMethod() error {
 	fa.AddEmptyLine()
 	fa.AddStatement(fa.ret)
 
-	e.Methods = append(e.Methods, GenerateFunctionString(fa))
+	fStr, err := GenerateFunctionString(fa)
+	if err != nil {
+		return err
+	}
+	e.Methods = append(e.Methods, fStr)
 
 	return nil
 }
@@ -226,7 +256,11 @@ func (e *Enum) AddSpellingMethodAlias(name string) error {
 
 	fa.AddStatement(fa.ret)
 
-	e.Methods = append(e.Methods, GenerateFunctionString(fa))
+	fStr, err := GenerateFunctionString(fa)
+	if err != nil {
+		return err
+	}
+	e.Methods = append(e.Methods, fStr)
 
 	return nil
 }
@@ -0,0 +1,43 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/go-clang/gen"
+)
+
+func TestEnumItemValue(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		e    *gen.Enum
+		ei   gen.EnumItem
+		want string
+	}{
+		"unsigned": {
+			e:    &gen.Enum{UnderlyingType: gen.GoUInt32},
+			ei:   gen.EnumItem{Value: 42},
+			want: "42",
+		},
+		"negative signed": {
+			e:    &gen.Enum{UnderlyingType: gen.GoInt32, IsSigned: true},
+			ei:   gen.EnumItem{Value: ^uint64(0)},
+			want: "-1",
+		},
+		"unsigned 64-bit": {
+			e:    &gen.Enum{UnderlyingType: gen.GoUInt64},
+			ei:   gen.EnumItem{Value: ^uint64(0)},
+			want: "18446744073709551615",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.e.ItemValue(tt.ei); got != tt.want {
+				t.Fatalf("ItemValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
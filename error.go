@@ -0,0 +1,47 @@
+package gen
+
+import (
+	"fmt"
+
+	"github.com/go-clang/bootstrap/clang"
+)
+
+// SymbolError represents an error of the generation of a C symbol.
+type SymbolError struct {
+	// Symbol holds the C name of the symbol, e.g. "clang_getCursorKind" or "CXCursor.kind" for struct fields.
+	Symbol string
+	// Location holds the source location of the symbol as "file:line:column", or is empty if it is unknown.
+	Location string
+
+	Err error
+}
+
+// Error implements the error interface.
+func (e *SymbolError) Error() string {
+	if e.Location == "" {
+		return fmt.Sprintf("%s: %v", e.Symbol, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s: %v", e.Location, e.Symbol, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SymbolError) Unwrap() error {
+	return e.Err
+}
+
+// cursorLocation returns the source location of cursor as "file:line:column".
+func cursorLocation(cursor clang.Cursor) string {
+	file, line, column, _ := cursor.Location().FileLocation()
+
+	return fmt.Sprintf("%s:%d:%d", file.Name(), line, column)
+}
+
+// newCursorError returns a *SymbolError for symbol at the location of cursor.
+func newCursorError(cursor clang.Cursor, symbol string, format string, args ...interface{}) error {
+	return &SymbolError{
+		Symbol:   symbol,
+		Location: cursorLocation(cursor),
+		Err:      fmt.Errorf(format, args...),
+	}
+}
@@ -0,0 +1,61 @@
+package gen_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-clang/gen"
+)
+
+func TestSymbolError(t *testing.T) {
+	t.Parallel()
+
+	errUnsupported := errors.New("unsupported type")
+
+	tests := map[string]struct {
+		err  *gen.SymbolError
+		want string
+	}{
+		"with location": {
+			err:  &gen.SymbolError{Symbol: "clang_getCursorKind", Location: "Index.h:12:5", Err: errUnsupported},
+			want: "Index.h:12:5: clang_getCursorKind: unsupported type",
+		},
+		"without location": {
+			err:  &gen.SymbolError{Symbol: "CXCursor.kind", Err: errUnsupported},
+			want: "CXCursor.kind: unsupported type",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.err.Error(); got != tt.want {
+				t.Fatalf("SymbolError.Error(): want: %q but got %q", tt.want, got)
+			}
+			if !errors.Is(tt.err, errUnsupported) {
+				t.Fatalf("errors.Is(%v, %v) = false", tt.err, errUnsupported)
+			}
+		})
+	}
+}
+
+func TestGeneration_GenerateCanceled(t *testing.T) {
+	t.Parallel()
+
+	h := gen.NewHeaderFile(&gen.API{}, "Index.h", "clang-c")
+	h.Functions = []*gen.Function{
+		{IncludeFiles: gen.NewIncludeFiles(), Name: "clang_getCursorKind", CName: "clang_getCursorKind"},
+	}
+
+	g := gen.NewGeneration(&gen.API{Output: gen.NewMemoryOutput()})
+	g.AddHeaderFiles([]*gen.HeaderFile{h})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := g.Generate(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Generate() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -15,3 +15,27 @@ func (g *Generation) Functions() []*Function {
 func (g *Generation) Structs() []*Struct {
 	return g.structs
 }
+
+type DlopenType = dlopenType
+
+func (g *Generation) DlopenTypeOf(typ Type) (DlopenType, bool) {
+	return g.dlopenTypeOf(typ)
+}
+
+func (g *Generation) AddDlopenFunction(f *Function) error {
+	return g.addDlopenFunction(f)
+}
+
+type AvailabilitySymbols = availabilitySymbols
+
+func (g *Generation) AvailabilitySince(trees []AvailabilitySymbols, symbol string) string {
+	return g.availabilitySince(trees, symbol)
+}
+
+func (g *Generation) GenerateCallbackHandlers() error {
+	return g.generateCallbackHandlers(map[string]string{})
+}
+
+func (g *Generation) AddVariables(clangFile *File) {
+	g.addVariables(clangFile)
+}
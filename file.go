@@ -2,37 +2,47 @@ package gen
 
 import (
 	"bytes"
-	"os"
-	"path/filepath"
+	"go/build/constraint"
+	"strings"
 	"text/template"
 
 	"golang.org/x/tools/imports"
 )
 
-// File represents a generation file.
+// File represents a generation file. It is the data of the file template, see DefaultFileTemplate.
 type File struct {
+	// Name holds the name of the file without the "_gen.go" suffix, e.g. "cursor".
 	Name string
 
+	// IncludeFiles holds the C headers which are included by the cgo preamble of the file.
 	IncludeFiles IncludeFiles
+	// UmbrellaHeader holds the C header which is included after IncludeFiles, see API.UmbrellaHeader.
+	UmbrellaHeader string
 
+	// Functions holds the rendered Go source of the functions without a receiver.
 	Functions []interface{}
-	Enums     []*Enum
-	Structs   []*Struct
+	// Enums holds the enums of the file. Their Methods hold the rendered Go source of the methods.
+	Enums []*Enum
+	// Structs holds the structs of the file. Their Methods hold the rendered Go source of the methods.
+	Structs []*Struct
 }
 
 // NewFile creates a new blank file.
 func NewFile(name string) *File {
 	return &File{
-		Name:         name,
-		IncludeFiles: NewIncludeFiles(),
+		Name:           name,
+		IncludeFiles:   NewIncludeFiles(),
+		UmbrellaHeader: DefaultUmbrellaHeader,
 	}
 }
 
-var templateGenerateFile = template.Must(template.New("go-clang-generate-file").Parse(`package clang
+// DefaultFileTemplate is the text of the default template of the generated Go files. The template is executed with
+// the *File which is generated. Its output is formatted and its imports are fixed before it is written.
+const DefaultFileTemplate = `package clang
 
 {{range $h, $dunno := $.IncludeFiles}}// #include "{{$h}}"
-{{end}}// #include "go-clang.h"
-import "C"
+{{end}}{{if $.UmbrellaHeader}}// #include "{{$.UmbrellaHeader}}"
+{{end}}import "C"
 
 {{range $i, $f := $.Functions}}
 {{$f}}
@@ -62,10 +72,21 @@ type {{$s.Name}} struct {
 {{$m}}
 {{end}}
 {{end}}
-`))
+`
+
+var templateGenerateFile = template.Must(NewFileTemplate(DefaultFileTemplate))
+
+// NewFileTemplate parses text as template of the generated Go files, see DefaultFileTemplate.
+func NewFileTemplate(text string) (*template.Template, error) {
+	return template.New("go-clang-generate-file").Parse(text)
+}
+
+// Generate generates file with the template tmpl and writes it to out. DefaultFileTemplate is used if tmpl is nil.
+func (f *File) Generate(out Output, tmpl *template.Template) error {
+	if tmpl == nil {
+		tmpl = templateGenerateFile
+	}
 
-// Generate generates file.
-func (f *File) Generate() error {
 	for _, e := range f.Enums {
 		f.IncludeFiles.unifyIncludeFiles(e.IncludeFiles)
 
@@ -96,27 +117,86 @@ func (f *File) Generate() error {
 	}
 
 	var b bytes.Buffer
-	if err := templateGenerateFile.Execute(&b, f); err != nil {
+	if err := tmpl.Execute(&b, f); err != nil {
 		return err
 	}
 
-	cwd, err := os.Getwd()
+	return writeGoFile(out, f.Name+"_gen.go", b.Bytes())
+}
+
+// Header represents the header of a generated file. It is the data of the header template, see DefaultHeaderTemplate.
+type Header struct {
+	// Name holds the name of the generated file, e.g. "cursor_gen.go" or "shims_gen.c".
+	Name string
+
+	// Constraint holds the build constraint of the file without the "//go:build" prefix, e.g. "!cgo", or is empty.
+	Constraint string
+}
+
+// PlusBuild returns the "// +build" lines of the build constraint of h for Go versions before 1.17.
+func (h Header) PlusBuild() (string, error) {
+	expr, err := constraint.Parse("//go:build " + h.Constraint)
+	if err != nil {
+		return "", err
+	}
+
+	lines, err := constraint.PlusBuildLines(expr)
 	if err != nil {
-		return err
+		return "", err
 	}
-	filename := filepath.Join(cwd, "clang", f.Name+"_gen.go")
 
-	bo := b.Bytes()
-	bo = bytes.ReplaceAll(bo, []byte(`#include "./clang/`), []byte(`#include "./`))
-	out, err := imports.Process(filename, bo, nil)
+	return strings.Join(lines, "\n"), nil
+}
+
+// DefaultHeaderTemplate is the text of the default template of the header which is written in front of every generated
+// Go, C and C header file, e.g. a license comment. The template is executed with the Header of the file. It has to
+// write the build constraint of the file since a file cannot have more than one. The header must only consist of line
+// comments, which are valid in all of these languages, and is separated from the content by a blank line.
+const DefaultHeaderTemplate = `{{if $.Constraint}}//go:build {{$.Constraint}}
+{{$.PlusBuild}}
+{{end}}`
+
+var templateGenerateHeader = template.Must(NewHeaderTemplate(DefaultHeaderTemplate))
+
+// NewHeaderTemplate parses text as template of the header of the generated files, see DefaultHeaderTemplate.
+func NewHeaderTemplate(text string) (*template.Template, error) {
+	return template.New("go-clang-generate-header").Parse(text)
+}
+
+// Generate generates the header with the template tmpl. DefaultHeaderTemplate is used if tmpl is nil.
+func (h Header) Generate(tmpl *template.Template) ([]byte, error) {
+	if tmpl == nil {
+		tmpl = templateGenerateHeader
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, h); err != nil {
+		return nil, err
+	}
+
+	if b.Len() > 0 && !bytes.HasSuffix(b.Bytes(), []byte("\n\n")) {
+		if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes(), nil
+}
+
+// writeGoFile formats b and writes it as name to out. The imports are resolved relative to the path of the file if out
+// writes into the file system.
+func writeGoFile(out Output, name string, b []byte) error {
+	bo := bytes.ReplaceAll(b, []byte(`#include "./clang/`), []byte(`#include "./`))
+	formatted, err := imports.Process(outputPath(out, name), bo, nil)
 	if err != nil {
 		// Write the file anyway so we can look at the problem
-		if err := os.WriteFile(filename, bo, 0600); err != nil {
+		if err := out.WriteFile(name, bo); err != nil {
 			return err
 		}
 
 		return err
 	}
 
-	return os.WriteFile(filename, out, 0600)
+	return out.WriteFile(name, formatted)
 }
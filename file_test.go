@@ -0,0 +1,120 @@
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-clang/gen"
+)
+
+func TestFile_GenerateTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		text       string
+		wantPrefix string
+		wantErr    bool
+	}{
+		"default": {
+			text:       gen.DefaultFileTemplate,
+			wantPrefix: "package clang\n",
+		},
+		"license header and build constraint": {
+			text:       "// Copyright 2026 The Authors.\n\n//go:build linux\n\n" + gen.DefaultFileTemplate,
+			wantPrefix: "// Copyright 2026 The Authors.\n\n//go:build linux\n\npackage clang\n",
+		},
+		"custom preamble": {
+			text: strings.Replace(gen.DefaultFileTemplate, `{{$.UmbrellaHeader}}`, `fork.h`, 1),
+			wantPrefix: "package clang\n\n" +
+				"// #include \"fork.h\"\n" +
+				"import \"C\"\n",
+		},
+		"invalid": {
+			text:    "package clang\n{{range}}",
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := gen.NewFileTemplate(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFileTemplate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			out := gen.NewMemoryOutput()
+			if err := gen.NewFile("clang").Generate(out, tmpl); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			if got := string(out.Files()["clang_gen.go"]); !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Fatalf("Generate(): want prefix %q but got %q", tt.wantPrefix, got)
+			}
+		})
+	}
+}
+
+func TestHeader_Generate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		text    string
+		header  gen.Header
+		want    string
+		wantErr bool
+	}{
+		"default": {
+			text:   gen.DefaultHeaderTemplate,
+			header: gen.Header{Name: "cursor_gen.go"},
+			want:   "",
+		},
+		"default with build constraint": {
+			text:   gen.DefaultHeaderTemplate,
+			header: gen.Header{Name: "dlopen_gen.go", Constraint: "!cgo"},
+			want:   "//go:build !cgo\n// +build !cgo\n\n",
+		},
+		"default with build constraint expression": {
+			text:   gen.DefaultHeaderTemplate,
+			header: gen.Header{Name: "shims_gen.c", Constraint: "cgo && !static"},
+			want:   "//go:build cgo && !static\n// +build cgo,!static\n\n",
+		},
+		"license header and build constraint": {
+			text:   "// Copyright 2026 The Authors.\n\n//go:build linux{{if $.Constraint}} && ({{$.Constraint}}){{end}}",
+			header: gen.Header{Name: "dlopen_gen.go", Constraint: "!cgo"},
+			want:   "// Copyright 2026 The Authors.\n\n//go:build linux && (!cgo)\n\n",
+		},
+		"invalid build constraint": {
+			text:    gen.DefaultHeaderTemplate,
+			header:  gen.Header{Name: "dlopen_gen.go", Constraint: "!"},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := gen.NewHeaderTemplate(tt.text)
+			if err != nil {
+				t.Fatalf("NewHeaderTemplate() error = %v", err)
+			}
+
+			got, err := tt.header.Generate(tmpl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("Generate(): want %q but got %q", tt.want, got)
+			}
+		})
+	}
+}
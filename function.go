@@ -1,7 +1,6 @@
 package gen
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/go-clang/bootstrap/clang"
@@ -14,11 +13,25 @@ type Function struct {
 	Name    string
 	CName   string
 	Comment string
+	// RawComment holds the raw Doxygen comment of the C function.
+	RawComment string
+	// Since holds the LLVM version which introduced the function, if known.
+	Since string
 
 	Parameters []FunctionParameter
 	ReturnType Type
 	Receiver   Receiver
 	Member     *FunctionParameter
+	// Variable holds the global variable which is read instead of calling a C function.
+	Variable *Variable
+	// IsVariadic whether the C function takes a variable number of arguments.
+	IsVariadic bool
+	// IsInline whether the C function is static or inline and therefore not an exported symbol.
+	IsInline bool
+	// Shim holds the name of the C function which is called instead of the C function itself, see Shim.
+	Shim string
+	// Location holds the source location of the C function as "file:line:column", or is empty if it is unknown.
+	Location string
 }
 
 // FunctionParameter represents a generation function parameter.
@@ -65,17 +78,20 @@ func NewFunction(name, cname, comment, member string, typ Type) *Function {
 }
 
 // HandleFunctionCursor handles function cursor.
-func HandleFunctionCursor(cursor clang.Cursor) *Function {
+func HandleFunctionCursor(cursor clang.Cursor) (*Function, error) {
 	fname := cursor.Spelling()
 	f := Function{
 		IncludeFiles: NewIncludeFiles(),
 		Name:         fname,
 		CName:        fname,
+		IsVariadic:   cursor.Type().IsFunctionTypeVariadic(),
+		IsInline:     cursor.IsFunctionInlined() || cursor.StorageClass() == clang.SC_Static,
+		Location:     cursorLocation(cursor),
 	}
 
 	typ, err := TypeFromClangType(cursor.ResultType())
 	if err != nil {
-		panic(fmt.Errorf("unexpected cursor.ResultType: %#v: %w", cursor.ResultType(), err))
+		return nil, newCursorError(cursor, fname, "cannot handle result type %q: %w", cursor.ResultType().Spelling(), err)
 	}
 	f.ReturnType = typ
 
@@ -90,39 +106,49 @@ func HandleFunctionCursor(cursor clang.Cursor) *Function {
 
 		typ, err := TypeFromClangType(param.Type())
 		if err != nil {
-			panic(fmt.Errorf("unexpected error: %w, param.Type(): %#v", err, param.Type()))
+			return nil, newCursorError(param, fname, "cannot handle type %q of parameter %q: %w", param.Type().Spelling(), p.CName, err)
 		}
 		p.Type = typ
-
-		p.Name = p.CName
-		if p.Name == "" {
-			p.Name = CommonReceiverName(p.Type.GoName)
-		} else {
-			pns := strings.Split(p.Name, "_")
-			for i := range pns {
-				pns[i] = UpperFirstCharacter(pns[i])
-			}
-			p.Name = LowerFirstCharacter(strings.Join(pns, ""))
-		}
-		if r := ReplaceGoKeywords(p.Name); r != "" {
-			p.Name = r
-		}
+		p.Name = ParameterName(p.CName, p.Type)
 
 		f.Parameters = append(f.Parameters, p)
 	}
 
 	commentFname := UpperFirstCharacter(strings.TrimPrefix(f.Name, "clang_"))
-	f.Comment = CleanDoxygenComment(commentFname, cursor.RawCommentText())
+	f.RawComment = cursor.RawCommentText()
+	f.Comment = CleanDoxygenComment(commentFname, f.RawComment)
 
-	return &f
+	return &f, nil
+}
+
+// ParameterName returns the Go name of a parameter with the C name cname and type typ.
+func ParameterName(cname string, typ Type) string {
+	name := cname
+	if name == "" {
+		name = CommonReceiverName(typ.GoName)
+	} else {
+		pns := strings.Split(name, "_")
+		for i := range pns {
+			pns[i] = UpperFirstCharacter(pns[i])
+		}
+		name = LowerFirstCharacter(strings.Join(pns, ""))
+	}
+	if r := ReplaceGoKeywords(name); r != "" {
+		name = r
+	}
+
+	return name
 }
 
 // Generate generates the function.
-func (f *Function) Generate() string {
+func (f *Function) Generate() (string, error) {
 	fa := NewASTFunc(f)
 	fa.Generate()
 
-	fStr := GenerateFunctionString(fa)
+	fStr, err := GenerateFunctionString(fa)
+	if err != nil {
+		return "", &SymbolError{Symbol: f.CName, Location: f.Location, Err: err}
+	}
 
 	// TODO(go-clang): find out how to position the comment correctly and do this using the AST
 	// https://github.com/go-clang/gen/issues/54
@@ -130,5 +156,5 @@ func (f *Function) Generate() string {
 		fStr = f.Comment + "\n" + fStr
 	}
 
-	return fStr
+	return fStr, nil
 }
@@ -1,6 +1,7 @@
 package gen
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"unicode"
@@ -15,6 +16,21 @@ type Generation struct {
 	enums     []*Enum
 	functions []*Function
 	structs   []*Struct
+	variables []*Variable
+
+	dlopenFunctions []dlopenFunction
+
+	shims []*Shim
+
+	report Report
+
+	cgoPointerErrors CgoPointerErrors
+
+	// callbackTables holds the names of the callback tables for which a handler was generated
+	callbackTables map[string]bool
+
+	// indexHeader holds the path of Index.h if it is part of the generation
+	indexHeader string
 }
 
 // NewGeneration returns the new *Generation from a.
@@ -30,6 +46,10 @@ func NewGeneration(a *API) *Generation {
 // AddHeaderFiles adds headerFiles to g.
 func (g *Generation) AddHeaderFiles(headerFiles []*HeaderFile) {
 	for _, h := range headerFiles {
+		if h.Filename == "Index.h" {
+			g.indexHeader = h.FullPath()
+		}
+
 		for _, e := range h.Enums {
 			g.enums = append(g.enums, e)
 			g.RegisterEnum(e)
@@ -41,15 +61,33 @@ func (g *Generation) AddHeaderFiles(headerFiles []*HeaderFile) {
 		}
 
 		g.functions = append(g.functions, h.Functions...)
+		g.variables = append(g.variables, h.Variables...)
 	}
 }
 
-// Generate Clang bindings generation.
-func (g *Generation) Generate() error {
+// Report returns the report of the generation.
+func (g *Generation) Report() *Report {
+	return &g.report
+}
+
+// Generate Clang bindings generation. It returns early if ctx is canceled.
+func (g *Generation) Generate(ctx context.Context) error {
+	if len(g.api.AvailabilityTrees) > 0 {
+		if err := g.ComputeAvailability(ctx); err != nil {
+			return fmt.Errorf("cannot compute availability: %w", err)
+		}
+	}
+
 	// prepare all functions
 	clangFile := NewFile("clang")
 
+	g.expandVariadicFunctions()
+
 	for _, f := range g.functions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		fname := f.Name
 		if g.api.PrepareFunctionName != nil {
 			fname = g.api.PrepareFunctionName(g, f)
@@ -60,6 +98,14 @@ func (g *Generation) Generate() error {
 			continue
 		}
 
+		if f.IsInline {
+			if err := g.addShim(f); err != nil {
+				g.report.Add(ReportSkipped, f.CName, "cannot generate shim for static or inline function: %v", err)
+
+				continue
+			}
+		}
+
 		// prepare the parameters
 		for i := range f.Parameters {
 			p := &f.Parameters[i]
@@ -81,6 +127,10 @@ func (g *Generation) Generate() error {
 			g.api.PrepareFunction(f)
 		}
 
+		if g.api.InferOutParameters {
+			g.InferOutParameters(f)
+		}
+
 		// prepare the return argument
 		if n, ok := g.LookupNonTypedef(f.ReturnType.CGoName); ok {
 			f.ReturnType.GoName = n
@@ -186,47 +236,82 @@ func (g *Generation) Generate() error {
 		}
 	}
 
+	g.addVariables(clangFile)
+
+	renames := g.applyTypeInitialisms()
+
+	if err := g.generateCallbackHandlers(renames); err != nil {
+		return err
+	}
+
 	for _, e := range g.enums {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		if err := e.AddEnumStringMethods(); err != nil {
 			return fmt.Errorf("cannot generate enum string methods: %w", err)
 		}
 
+		g.applyFunctionInitialisms(e.Methods, renames)
+		g.fixFunctionNames(e.Methods)
+
+		if err := g.ResolveNameConflicts(e.Name, e.Methods); err != nil {
+			return err
+		}
+
 		for i, m := range e.Methods {
-			e.Methods[i] = g.GenerateMethod(e.Name, m)
+			src, err := g.GenerateMethod(e.Name, m)
+			if err != nil {
+				return err
+			}
+			e.Methods[i] = src
 
 			switch m := m.(type) {
 			case *Function:
-				if len(m.Parameters) > 0 && !m.Parameters[0].Type.IsSlice && m.Parameters[0].Type.GoName == e.Name {
-					m.Receiver = Receiver{
-						Name: CommonReceiverName(e.Name),
-						Type: m.Parameters[0].Type,
-					}
-
-					g.SetIsPointerComposition(&m.Receiver.Type)
-				}
-
-				for i := range m.Parameters {
-					g.SetIsPointerComposition(&m.Parameters[i].Type)
-				}
-
 				e.IncludeFiles.unifyIncludeFiles(m.IncludeFiles)
-
-				e.Methods[i] = m.Generate()
 			}
 		}
 
-		if err := e.Generate(); err != nil {
+		if err := g.generateFile(e.File()); err != nil {
 			return fmt.Errorf("cannot generate enum: %w", err)
 		}
 	}
 
 	for _, s := range g.structs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		if err := s.AddFieldGetters(); err != nil {
 			return fmt.Errorf("cannot generate struct member getters: %w", err)
 		}
 
+		if err := g.AddBitFieldGetters(s); err != nil {
+			return fmt.Errorf("cannot generate struct bitfield getters: %w", err)
+		}
+
+		g.applyFunctionInitialisms(s.Methods, renames)
+		g.fixFunctionNames(s.Methods)
+
+		if err := g.AddSliceAccessors(s); err != nil {
+			return fmt.Errorf("cannot generate struct slice accessors: %w", err)
+		}
+
+		if err := g.AddIsNullMethod(s); err != nil {
+			return fmt.Errorf("cannot generate struct IsNull method: %w", err)
+		}
+
+		if err := g.ResolveNameConflicts(s.Name, s.Methods); err != nil {
+			return err
+		}
+
 		for i, m := range s.Methods {
-			s.Methods[i] = g.GenerateMethod(s.Name, m)
+			src, err := g.GenerateMethod(s.Name, m)
+			if err != nil {
+				return err
+			}
+			s.Methods[i] = src
 
 			switch m := m.(type) {
 			case *Function:
@@ -234,12 +319,19 @@ func (g *Generation) Generate() error {
 			}
 		}
 
-		if err := s.Generate(); err != nil {
+		if err := g.generateFile(s.File()); err != nil {
 			return fmt.Errorf("cannot generate struct: %w", err)
 		}
 	}
 
 	if len(clangFile.Functions) > 0 {
+		g.applyFunctionInitialisms(clangFile.Functions, renames)
+		g.fixFunctionNames(clangFile.Functions)
+
+		if err := g.ResolveNameConflicts("", clangFile.Functions); err != nil {
+			return err
+		}
+
 		for _, m := range clangFile.Functions {
 			switch m := m.(type) {
 			case *Function:
@@ -248,27 +340,124 @@ func (g *Generation) Generate() error {
 		}
 
 		for i, m := range clangFile.Functions {
-			clangFile.Functions[i] = g.GenerateMethod("", m)
+			src, err := g.GenerateMethod("", m)
+			if err != nil {
+				return err
+			}
+			clangFile.Functions[i] = src
 		}
 
-		if err := clangFile.Generate(); err != nil {
+		if err := g.generateFile(clangFile); err != nil {
 			return fmt.Errorf("cannot generate clang file: %w", err)
 		}
 	}
 
+	if len(g.shims) > 0 {
+		if err := g.GenerateShims(); err != nil {
+			return fmt.Errorf("cannot generate shims: %w", err)
+		}
+	}
+
+	if g.api.hasCgoFlags() {
+		if err := g.GenerateCgoFlags(); err != nil {
+			return fmt.Errorf("cannot generate cgo flags: %w", err)
+		}
+	}
+
+	if len(g.cgoPointerErrors) > 0 {
+		return fmt.Errorf("cannot generate cgocheck clean bindings:\n%w", g.cgoPointerErrors)
+	}
+
+	if g.hasABIGuard() {
+		if err := g.GenerateABIGuard(); err != nil {
+			return fmt.Errorf("cannot generate ABI guard: %w", err)
+		}
+	}
+
+	if g.api.Backend == BackendDlopen {
+		if err := g.GenerateDlopen(); err != nil {
+			return fmt.Errorf("cannot generate dlopen file: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// applyTypeInitialisms applies the initialisms of the API to the names of all enums, enum items and structs and returns
+// the renamed types as mapping from their old to their new names.
+func (g *Generation) applyTypeInitialisms() map[string]string {
+	renames := map[string]string{}
+	if len(g.api.Initialisms) == 0 {
+		return renames
+	}
+
+	for _, e := range g.enums {
+		if name := ApplyInitialisms(e.Name, g.api.Initialisms); name != e.Name {
+			renames[e.Name] = name
+			g.RenameEnum(e, name)
+		}
+		e.Receiver.Type.GoName = e.Name
+
+		for i := range e.Items {
+			e.Items[i].Name = ApplyInitialisms(e.Items[i].Name, g.api.Initialisms)
+		}
+	}
+
+	for _, s := range g.structs {
+		if name := ApplyInitialisms(s.Name, g.api.Initialisms); name != s.Name {
+			renames[s.Name] = name
+			g.RenameStruct(s, name)
+		}
+		s.Receiver.Type.GoName = s.Name
+	}
+
+	return renames
+}
+
+// applyFunctionInitialisms applies the initialisms of the API to the names of the functions in methods, their parameters
+// and the types they reference. The C names of struct members are never renamed.
+func (g *Generation) applyFunctionInitialisms(methods []interface{}, renames map[string]string) {
+	if len(g.api.Initialisms) == 0 {
+		return
+	}
+
+	renameType := func(typ *Type) {
+		if name, ok := renames[typ.GoName]; ok {
+			typ.GoName = name
+		}
+	}
+
+	for _, m := range methods {
+		f, ok := m.(*Function)
+		if !ok {
+			continue
+		}
+
+		f.Name = ApplyInitialisms(f.Name, g.api.Initialisms)
+		renameType(&f.ReturnType)
+		renameType(&f.Receiver.Type)
+
+		for i := range f.Parameters {
+			p := &f.Parameters[i]
+
+			p.Name = ApplyInitialisms(p.Name, g.api.Initialisms)
+			p.Type.LengthOfSlice = ApplyInitialisms(p.Type.LengthOfSlice, g.api.Initialisms)
+			renameType(&p.Type)
+		}
+	}
+}
+
+// generateFile generates f with the file template and the umbrella header of the generation.
+func (g *Generation) generateFile(f *File) error {
+	f.UmbrellaHeader = g.umbrellaHeader()
+
+	return f.Generate(g.output(), g.api.FileTemplate)
+}
+
 // GenerateMethod method generation.
-func (g *Generation) GenerateMethod(receiverName string, m interface{}) string {
+func (g *Generation) GenerateMethod(receiverName string, m interface{}) (string, error) {
 	switch m := m.(type) {
 	case *Function:
-		if g.api.FixFunctionName != nil {
-			if fname := g.api.FixFunctionName(m); fname != "" {
-				m.Name = fname
-			}
-		}
-
 		if len(m.Parameters) > 0 && !m.Parameters[0].Type.IsSlice && m.Parameters[0].Type.GoName == receiverName {
 			m.Receiver = Receiver{
 				Name: CommonReceiverName(receiverName),
@@ -282,16 +471,45 @@ func (g *Generation) GenerateMethod(receiverName string, m interface{}) string {
 			g.SetIsPointerComposition(&m.Parameters[i].Type)
 		}
 		g.SetIsPointerComposition(&m.ReturnType)
+		m.ReturnType.IsNullable = g.isNullableFunction(m)
+
+		g.cgoPointerErrors = append(g.cgoPointerErrors, g.CheckCgoPointers(m)...)
+
+		// borrowed C arrays are always copied since libclang may release them while the slice is still in use
+		for i := range m.Parameters {
+			if p := &m.Parameters[i]; p.Type.IsSlice && p.Type.IsReturnArgument {
+				p.Type.IsCopiedSlice = g.api.CopyCArrays || p.Type.Ownership == OwnershipBorrowed
+			}
+		}
+		m.ReturnType.IsCopiedSlice = m.ReturnType.IsSlice && (g.api.CopyCArrays || m.ReturnType.Ownership == OwnershipBorrowed)
 
 		m.Comment = strings.ReplaceAll(m.Comment, strings.TrimPrefix(m.CName, "clang_"), m.Name)
 
-		return m.Generate()
+		if g.api.Backend == BackendDlopen && m.Member == nil && m.Variable == nil && m.Shim == "" {
+			if err := g.addDlopenFunction(m); err != nil {
+				g.report.Add(ReportSkipped, m.CName, "not supported by the dlopen backend: %v", err)
+			}
+		}
+
+		src, err := m.Generate()
+		if err != nil {
+			return "", err
+		}
+
+		wrapper, ok, err := g.GenerateCallbackTableWrapper(m, src)
+		if err != nil {
+			g.report.Add(ReportSkipped, m.CName, "cannot generate handler variant: %v", err)
+		} else if ok {
+			src += "\n\n" + wrapper
+		}
+
+		return src, nil
 
 	case string:
-		return m
+		return m, nil
 
 	default:
-		panic(fmt.Sprintf("Cannot handle %v in handleMethod", m))
+		return "", fmt.Errorf("cannot generate method of type %T for %q", m, receiverName)
 	}
 }
 
@@ -1,6 +1,6 @@
 module github.com/go-clang/gen
 
-go 1.17
+go 1.23
 
 require (
 	github.com/go-clang/bootstrap v0.14.0
@@ -1,6 +1,7 @@
 package gen
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -23,6 +24,7 @@ type HeaderFile struct {
 	Enums     []*Enum
 	Functions []*Function
 	Structs   []*Struct
+	Variables []*Variable
 }
 
 // NewHeaderFile returns the new initialized HeaderFile.
@@ -78,8 +80,8 @@ func (h *HeaderFile) PrepareFile() error {
 	return nil
 }
 
-// HandleFile handles header file.
-func (h *HeaderFile) HandleFile(cursor clang.Cursor) {
+// HandleFile handles header file. It returns early if ctx is canceled.
+func (h *HeaderFile) HandleFile(ctx context.Context, cursor clang.Cursor) error {
 	// TODO(go-clang): mark the enum https://github.com/go-clang/gen/issues/40
 	//  typedef enum CXChildVisitResult (*CXCursorVisitor)(CXCursor cursor, CXCursor parent, CXClientData client_data);
 	// as manually implemented
@@ -87,7 +89,12 @@ func (h *HeaderFile) HandleFile(cursor clang.Cursor) {
 	// TODO(go-clang): report other enums like callbacks that they are not implemented
 	// https://github.com/go-clang/gen/issues/51
 
+	var err error
 	cursor.Visit(func(cursor, parent clang.Cursor) clang.ChildVisitResult {
+		if err = ctx.Err(); err != nil {
+			return clang.ChildVisit_Break
+		}
+
 		// only handle code of the current file
 		sourceFile, _, _, _ := cursor.Location().FileLocation()
 		isCurrentFile := sourceFile.Name() == h.FullPath()
@@ -115,7 +122,10 @@ func (h *HeaderFile) HandleFile(cursor clang.Cursor) {
 				break
 			}
 
-			e := HandleEnumCursor(cursor, cname, cnameIsTypeDef)
+			var e *Enum
+			if e, err = HandleEnumCursor(cursor, cname, cnameIsTypeDef); err != nil {
+				return clang.ChildVisit_Break
+			}
 			e.IncludeFiles.AddIncludeFile(sourceFile.Name())
 
 			if _, ok := h.HasEnum(e.Name); !ok {
@@ -127,19 +137,34 @@ func (h *HeaderFile) HandleFile(cursor clang.Cursor) {
 				return clang.ChildVisit_Continue
 			}
 
-			f := HandleFunctionCursor(cursor)
-			if f != nil {
-				f.IncludeFiles.AddIncludeFile(sourceFile.Name())
-				h.Functions = append(h.Functions, f)
+			var f *Function
+			if f, err = HandleFunctionCursor(cursor); err != nil {
+				return clang.ChildVisit_Break
+			}
+			f.IncludeFiles.AddIncludeFile(sourceFile.Name())
+			h.Functions = append(h.Functions, f)
+
+		case clang.Cursor_VarDecl:
+			// only handle global variables, not the ones of inline function bodies
+			if !isCurrentFile || cursor.SemanticParent().Kind() != clang.Cursor_TranslationUnit {
+				return clang.ChildVisit_Continue
 			}
 
+			v := HandleVariableCursor(cursor)
+			v.IncludeFiles.AddIncludeFile(sourceFile.Name())
+			h.Variables = append(h.Variables, v)
+
 		case clang.Cursor_StructDecl:
 			if cname == "" {
 				break
 			}
 
-			s := HandleStructCursor(cursor, cname, cnameIsTypeDef)
+			var s *Struct
+			if s, err = HandleStructCursor(cursor, cname, cnameIsTypeDef); err != nil {
+				return clang.ChildVisit_Break
+			}
 			s.api = h.api
+			s.IsPointerTypedef = cnameIsTypeDef && parent.TypedefDeclUnderlyingType().CanonicalType().Kind() == clang.Type_Pointer
 			s.IncludeFiles.AddIncludeFile(sourceFile.Name())
 
 			if _, ok := h.HasStruct(s.Name); !ok {
@@ -153,14 +178,19 @@ func (h *HeaderFile) HandleFile(cursor clang.Cursor) {
 
 			if s, ok := h.HasStruct(underlyingStructType); ok && !s.CNameIsTypeDef && strings.HasPrefix(underlyingType, "struct "+s.CName) {
 				// sometimes the typedef is not a parent of the struct but a sibling
-				sn := HandleStructCursor(cursor, cname, true)
+				var sn *Struct
+				if sn, err = HandleStructCursor(cursor, cname, true); err != nil {
+					return clang.ChildVisit_Break
+				}
 				sn.api = h.api
+				sn.IsPointerTypedef = strings.HasSuffix(underlyingType, "*")
 				sn.IncludeFiles.AddIncludeFile(sourceFile.Name())
 
 				if sn.Comment == "" {
 					sn.Comment = s.Comment
 				}
 				sn.Fields = s.Fields
+				sn.Callbacks = s.Callbacks
 				sn.Methods = s.Methods
 
 				h.RemoveStruct(s)
@@ -174,8 +204,12 @@ func (h *HeaderFile) HandleFile(cursor clang.Cursor) {
 					}
 				}
 			} else if underlyingType == "void *" {
-				s := HandleStructCursor(cursor, cname, true)
+				var s *Struct
+				if s, err = HandleStructCursor(cursor, cname, true); err != nil {
+					return clang.ChildVisit_Break
+				}
 				s.api = h.api
+				s.IsPointerTypedef = true
 				s.IncludeFiles.AddIncludeFile(sourceFile.Name())
 
 				if _, ok := h.HasStruct(s.Name); !ok {
@@ -187,14 +221,21 @@ func (h *HeaderFile) HandleFile(cursor clang.Cursor) {
 
 		return clang.ChildVisit_Recurse
 	})
+
+	return err
 }
 
 // Parse parses header file with clangArguments.
-func (h *HeaderFile) Parse(clangArguments []string) error {
+func (h *HeaderFile) Parse(ctx context.Context, clangArguments []string) error {
 	if err := h.PrepareFile(); err != nil {
 		return err
 	}
 
+	return h.parse(ctx, clangArguments)
+}
+
+// parse parses header file with clangArguments without preparing it.
+func (h *HeaderFile) parse(ctx context.Context, clangArguments []string) error {
 	// parse the header file to analyse everything we need to know
 	idx := clang.NewIndex(0, 1)
 	defer idx.Dispose()
@@ -216,9 +257,7 @@ func (h *HeaderFile) Parse(clangArguments []string) error {
 		}
 	}
 
-	h.HandleFile(tu.TranslationUnitCursor())
-
-	return nil
+	return h.HandleFile(ctx, tu.TranslationUnitCursor())
 }
 
 // FullPath returns the full path of h.
@@ -81,3 +81,19 @@ func (l *Lookup) IsEnumOrStruct(name string) bool {
 
 	return false
 }
+
+// RenameEnum renames e *Enum to name and registers it to Lookup under its new name.
+func (l *Lookup) RenameEnum(e *Enum, name string) {
+	delete(l.lookupEnum, e.Name)
+	e.Name = name
+	l.lookupEnum[e.Name] = e
+	l.lookupNonTypedefs[fmt.Sprintf("enum %s", e.CName)] = e.Name
+}
+
+// RenameStruct renames s *Struct to name and registers it to Lookup under its new name.
+func (l *Lookup) RenameStruct(s *Struct, name string) {
+	delete(l.lookupStruct, s.Name)
+	s.Name = name
+	l.lookupStruct[s.Name] = s
+	l.lookupNonTypedefs[fmt.Sprintf("struct %s", s.CName)] = s.Name
+}
@@ -114,3 +114,100 @@ func CommonReceiverName(s string) string {
 
 	return string(n)
 }
+
+// CommonInitialisms holds common initialisms which are kept in their canonical spelling by ApplyInitialisms.
+var CommonInitialisms = []string{
+	"API",
+	"ASCII",
+	"AST",
+	"CXX",
+	"ID",
+	"JSON",
+	"ObjC",
+	"PCH",
+	"URL",
+	"USR",
+	"UTF8",
+	"UUID",
+}
+
+// ApplyInitialisms replaces all words of name which match one of the initialisms case-insensitively with the
+// initialism's canonical spelling, e.g. "Usr" with "USR" and "Objc" with "ObjC".
+//
+// A word starts at the beginning of name, after an underscore, after a digit or at an upper case character following a
+// lower case character or directly after another initialism, and ends before an underscore, a digit, an upper case
+// character or the end of name. A word at the beginning of a name with a lower case first character is replaced by the
+// lower case initialism.
+func ApplyInitialisms(name string, initialisms []string) string {
+	if len(initialisms) == 0 || name == "" {
+		return name
+	}
+
+	r := []rune(name)
+
+	var s strings.Builder
+	s.Grow(len(name))
+
+	matched := false
+	for i := 0; i < len(r); {
+		if matched || isWordStart(r, i) {
+			if in, ok := matchInitialism(r, i, initialisms); ok {
+				if i == 0 && unicode.IsLower(r[0]) {
+					in = strings.ToLower(in)
+				}
+
+				s.WriteString(in)
+				i += len([]rune(in))
+				matched = true
+
+				continue
+			}
+		}
+
+		s.WriteRune(r[i])
+		i++
+		matched = false
+	}
+
+	return s.String()
+}
+
+// isWordStart reports whether a word starts at index i of r.
+func isWordStart(r []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	p, c := r[i-1], r[i]
+
+	switch {
+	case p == '_':
+		return true
+
+	case unicode.IsDigit(p):
+		return !unicode.IsDigit(c)
+
+	case unicode.IsLower(p):
+		return unicode.IsUpper(c)
+	}
+
+	return false
+}
+
+// matchInitialism returns the initialism which matches the word starting at index i of r.
+func matchInitialism(r []rune, i int, initialisms []string) (string, bool) {
+	for _, in := range initialisms {
+		ir := []rune(in)
+
+		end := i + len(ir)
+		if end > len(r) || !strings.EqualFold(string(r[i:end]), in) {
+			continue
+		}
+
+		if end == len(r) || r[end] == '_' || unicode.IsDigit(r[end]) || unicode.IsUpper(r[end]) {
+			return in, true
+		}
+	}
+
+	return "", false
+}
@@ -95,3 +95,35 @@ func TestTrimCommonFunctionNamePrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyInitialisms(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		name string
+		want string
+	}{
+		"Usr":                   {name: "Usr", want: "USR"},
+		"usr":                   {name: "usr", want: "usr"},
+		"CursorUsr":             {name: "CursorUsr", want: "CursorUSR"},
+		"ConstructUsr_ObjCIvar": {name: "ConstructUsr_ObjcIvar", want: "ConstructUSR_ObjCIvar"},
+		"CxxMethod":             {name: "CxxMethod", want: "CXXMethod"},
+		"CXXMethod":             {name: "CXXMethod", want: "CXXMethod"},
+		"UsrId":                 {name: "UsrId", want: "USRID"},
+		"IdxEntity":             {name: "IdxEntity", want: "IdxEntity"},
+		"Identifier":            {name: "Identifier", want: "Identifier"},
+		"FileUniqueId":          {name: "FileUniqueId", want: "FileUniqueID"},
+		"outId":                 {name: "outId", want: "outID"},
+		"Url2":                  {name: "Url2", want: "URL2"},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := gen.ApplyInitialisms(tt.name, gen.CommonInitialisms); got != tt.want {
+				t.Fatalf("ApplyInitialisms(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
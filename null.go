@@ -0,0 +1,74 @@
+package gen
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// NullHandling defines how structs which wrap a C pointer expose whether the pointer is NULL.
+type NullHandling int
+
+const (
+	// NullHandlingNone generates no NULL handling, callers have to use hand-written methods like IsValid.
+	NullHandlingNone NullHandling = iota
+	// NullHandlingIsNull generates an IsNull method for every struct which wraps a C pointer.
+	NullHandlingIsNull
+	// NullHandlingOK generates an IsNull method for every struct which wraps a C pointer and additionally returns
+	// whether the pointer is not NULL from every function which returns such a struct, e.g. "(File, bool)", unless
+	// API.FilterNullableFunction excludes the function.
+	NullHandlingOK
+)
+
+var templateGenerateIsNull = template.Must(template.New("go-clang-generate-is-null").Parse(`// IsNull reports whether the underlying C pointer is NULL.
+func ({{$.Receiver.Name}} {{if $.IsPointerComposition}}*{{end}}{{$.Name}}) IsNull() bool {
+	return {{if $.IsPointerComposition}}{{$.Receiver.Name}} == nil || {{end}}{{$.Receiver.Name}}.c == nil
+}`))
+
+// AddIsNullMethod adds an IsNull method to s if s wraps a C pointer and the API generates NULL handling.
+func (g *Generation) AddIsNullMethod(s *Struct) error {
+	if g.api.NullHandling == NullHandlingNone || !(s.IsPointerTypedef || s.IsPointerComposition) || s.ContainsMethod("IsNull") {
+		return nil
+	}
+
+	var b bytes.Buffer
+	if err := templateGenerateIsNull.Execute(&b, s); err != nil {
+		return err
+	}
+
+	s.Methods = append(s.Methods, b.String())
+
+	return nil
+}
+
+// IsNullableType reports whether typ wraps a C pointer and is therefore returned together with whether it is not NULL.
+// These are opaque handles, e.g. "(File, bool)", and pointers to structs which are composed by their C pointer, e.g.
+// "(*CodeCompleteResults, bool)".
+func (g *Generation) IsNullableType(typ Type) bool {
+	if g.api.NullHandling != NullHandlingOK || typ.IsSlice || typ.IsArray {
+		return false
+	}
+
+	s, ok := g.HasStruct(typ.GoName)
+	if !ok {
+		return false
+	}
+
+	switch typ.PointerLevel {
+	case 0:
+		return s.IsPointerTypedef
+	case 1:
+		return s.IsPointerComposition
+	}
+
+	return false
+}
+
+// isNullableFunction reports whether the result of f is returned together with whether it is not NULL, i.e. whether
+// it is nullable and API.FilterNullableFunction does not exclude f.
+func (g *Generation) isNullableFunction(f *Function) bool {
+	if !g.IsNullableType(f.ReturnType) {
+		return false
+	}
+
+	return g.api.FilterNullableFunction == nil || g.api.FilterNullableFunction(f)
+}
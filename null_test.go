@@ -0,0 +1,141 @@
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-clang/gen"
+)
+
+func TestIsNullableType(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mode gen.NullHandling
+		typ  gen.Type
+		want bool
+	}{
+		"none": {
+			mode: gen.NullHandlingNone,
+			typ:  gen.Type{GoName: "File"},
+			want: false,
+		},
+		"is null": {
+			mode: gen.NullHandlingIsNull,
+			typ:  gen.Type{GoName: "File"},
+			want: false,
+		},
+		"handle": {
+			mode: gen.NullHandlingOK,
+			typ:  gen.Type{GoName: "File"},
+			want: true,
+		},
+		"pointer to handle": {
+			mode: gen.NullHandlingOK,
+			typ:  gen.Type{GoName: "File", PointerLevel: 1},
+			want: false,
+		},
+		"pointer composition": {
+			mode: gen.NullHandlingOK,
+			typ:  gen.Type{GoName: "CodeCompleteResults", PointerLevel: 1},
+			want: true,
+		},
+		"pointer composition value": {
+			mode: gen.NullHandlingOK,
+			typ:  gen.Type{GoName: "CodeCompleteResults"},
+			want: false,
+		},
+		"value struct": {
+			mode: gen.NullHandlingOK,
+			typ:  gen.Type{GoName: "Cursor"},
+			want: false,
+		},
+		"primitive": {
+			mode: gen.NullHandlingOK,
+			typ:  gen.Type{GoName: gen.GoInt32, IsPrimitive: true},
+			want: false,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g := gen.NewGeneration(&gen.API{
+				NullHandling: tt.mode,
+			})
+			g.RegisterStruct(&gen.Struct{Name: "File", CName: "CXFile", IsPointerTypedef: true})
+			g.RegisterStruct(&gen.Struct{Name: "Cursor", CName: "CXCursor"})
+			g.RegisterStruct(&gen.Struct{Name: "CodeCompleteResults", CName: "CXCodeCompleteResults", IsPointerComposition: true})
+
+			if got := g.IsNullableType(tt.typ); got != tt.want {
+				t.Fatalf("IsNullableType(%#v) = %t, want %t", tt.typ, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneration_GenerateMethodNullable(t *testing.T) {
+	t.Parallel()
+
+	database := gen.Type{CName: "CXCompilationDatabase", CGoName: "CXCompilationDatabase", GoName: "CompilationDatabase"}
+	results := gen.Type{CName: "CXCodeCompleteResults *", CGoName: "CXCodeCompleteResults", GoName: "CodeCompleteResults", PointerLevel: 1}
+
+	fromDirectory := func() *gen.Function {
+		return &gen.Function{
+			Name:  "FromDirectory",
+			CName: "clang_CompilationDatabase_fromDirectory",
+			Parameters: []gen.FunctionParameter{
+				{Name: "buildDir", CName: "BuildDir", Type: gen.Type{CName: "const char *", CGoName: gen.CSChar, GoName: "string", PointerLevel: 1, IsPrimitive: true}},
+				{Name: "errorCode", CName: "ErrorCode", Type: gen.Type{CName: "CXCompilationDatabase_Error *", CGoName: "CXCompilationDatabase_Error", GoName: "CompilationDatabase_Error", PointerLevel: 1, IsPrimitive: true, IsEnumLiteral: true, IsReturnArgument: true}},
+			},
+			ReturnType: database,
+		}
+	}
+
+	tests := map[string]struct {
+		filter func(f *gen.Function) bool
+		f      *gen.Function
+	}{
+		"out-parameter": {
+			f: fromDirectory(),
+		},
+		"pointer composition": {
+			f: &gen.Function{
+				Name:  "CodeCompleteAt",
+				CName: "clang_codeCompleteAt",
+				Parameters: []gen.FunctionParameter{
+					{Name: "tu", CName: "TU", Type: gen.Type{CName: "CXTranslationUnit", CGoName: "CXTranslationUnit", GoName: "TranslationUnit"}},
+				},
+				ReturnType: results,
+			},
+		},
+		"filtered": {
+			filter: func(f *gen.Function) bool {
+				return f.Name != "FromDirectory"
+			},
+			f: fromDirectory(),
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g := gen.NewGeneration(&gen.API{
+				NullHandling:           gen.NullHandlingOK,
+				FilterNullableFunction: tt.filter,
+			})
+			g.RegisterStruct(&gen.Struct{Name: "CompilationDatabase", CName: "CXCompilationDatabase", IsPointerTypedef: true})
+			g.RegisterStruct(&gen.Struct{Name: "TranslationUnit", CName: "CXTranslationUnit", IsPointerTypedef: true})
+			g.RegisterStruct(&gen.Struct{Name: "CodeCompleteResults", CName: "CXCodeCompleteResults", IsPointerComposition: true})
+
+			src, err := g.GenerateMethod(tt.f.Parameters[0].Type.GoName, tt.f)
+			if err != nil {
+				t.Fatalf("GenerateMethod() error = %v", err)
+			}
+
+			checkGolden(t, "null/"+strings.ReplaceAll(name, " ", "_")+".go", []byte(src+"\n"))
+		})
+	}
+}
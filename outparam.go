@@ -0,0 +1,127 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ParameterDirection defines the direction of a parameter as documented in a Doxygen comment.
+type ParameterDirection int
+
+const (
+	// ParameterDirectionUnknown the direction of the parameter is not documented.
+	ParameterDirectionUnknown ParameterDirection = iota
+	// ParameterDirectionIn the parameter is only read by the function.
+	ParameterDirectionIn
+	// ParameterDirectionOut the parameter is only written by the function.
+	ParameterDirectionOut
+	// ParameterDirectionInOut the parameter is read and written by the function.
+	ParameterDirectionInOut
+)
+
+var (
+	reParameterDocumentation = regexp.MustCompile(`[\\@]param\s*(?:\[\s*(in|out|in\s*,\s*out)\s*\])?\s+(\w+)(?:\s*\[\s*(in|out|in\s*,\s*out)\s*\])?`)
+	reDocumentationCommand   = regexp.MustCompile(`[\\@](?:param|returns?|brief|note|see|sa|code|verbatim)\b`)
+)
+
+// ParameterDirections returns the documented directions of the parameters in the raw Doxygen comment, keyed by their
+// C names. A parameter is an output if its direction is explicitly "[out]", before or after its name as libclang
+// documents it, or if its documentation states that it "will be set to" a value.
+func ParameterDirections(comment string) map[string]ParameterDirection {
+	directions := map[string]ParameterDirection{}
+
+	comment = reReplaceCComments.ReplaceAllString(comment, "\n")
+
+	matches := reParameterDocumentation.FindAllStringSubmatchIndex(comment, -1)
+	for i, m := range matches {
+		direction := ParameterDirectionUnknown
+		if dm := m[2:4]; dm[0] >= 0 || m[6] >= 0 {
+			if dm[0] < 0 {
+				dm = m[6:8]
+			}
+
+			switch d := strings.Join(strings.Fields(strings.ReplaceAll(comment[dm[0]:dm[1]], ",", " ")), ","); d {
+			case "in":
+				direction = ParameterDirectionIn
+			case "out":
+				direction = ParameterDirectionOut
+			default:
+				direction = ParameterDirectionInOut
+			}
+		}
+
+		// the documentation of the parameter ends at the next command or empty line
+		text := comment[m[1]:]
+		if i+1 < len(matches) {
+			text = comment[m[1]:matches[i+1][0]]
+		}
+		if loc := reDocumentationCommand.FindStringIndex(text); loc != nil {
+			text = text[:loc[0]]
+		}
+		if j := strings.Index(text, "\n\n"); j >= 0 {
+			text = text[:j]
+		}
+		text = strings.Join(strings.Fields(text), " ")
+
+		if direction == ParameterDirectionUnknown && strings.Contains(strings.ToLower(text), "will be set to") {
+			direction = ParameterDirectionOut
+		}
+
+		directions[comment[m[4]:m[5]]] = direction
+	}
+
+	return directions
+}
+
+// isOutParameterCandidate reports whether the parameter typ can be generated as return argument, which are non-const
+// pointers to primitives, enums and records.
+func (g *Generation) isOutParameterCandidate(typ Type) bool {
+	if typ.PointerLevel != 1 || typ.IsSlice || typ.IsArray || typ.IsFunctionPointer || typ.LengthOfSlice != "" || typ.IsConstAt(1) {
+		return false
+	}
+
+	switch {
+	case typ.CGoName == CSChar, typ.GoName == "void", typ.GoName == GoBool:
+		return false
+
+	case typ.GoName == "cxstring", IsInteger(&typ), typ.GoName == GoFloat32, typ.GoName == GoFloat64:
+		return true
+	}
+
+	return g.IsEnumOrStruct(typ.GoName)
+}
+
+// InferOutParameters marks the parameters of f as return arguments which are documented as outputs in the raw comment
+// of f. Parameters whose documented direction contradicts their type, or which could be outputs but are not
+// documented, are added to the report.
+func (g *Generation) InferOutParameters(f *Function) {
+	directions := map[string]ParameterDirection{}
+	for cname, d := range ParameterDirections(f.RawComment) {
+		// the C names of enum parameters are replaced, so the documentation is matched by the Go names
+		directions[ParameterName(cname, Type{})] = d
+	}
+
+	for i := range f.Parameters {
+		p := &f.Parameters[i]
+		if p.Type.IsReturnArgument {
+			continue
+		}
+
+		d := directions[p.Name]
+		candidate := g.isOutParameterCandidate(p.Type)
+
+		switch {
+		case d == ParameterDirectionOut && candidate:
+			p.Type.IsReturnArgument = true
+
+		case d == ParameterDirectionOut:
+			g.report.Add(ReportAmbiguous, f.CName, "parameter %q is documented as output but its type %q cannot be returned", p.CName, p.Type.CName)
+
+		case d == ParameterDirectionInOut && candidate:
+			g.report.Add(ReportAmbiguous, f.CName, "parameter %q is documented as input and output", p.CName)
+
+		case d == ParameterDirectionUnknown && candidate:
+			g.report.Add(ReportAmbiguous, f.CName, "parameter %q of type %q has no documented direction", p.CName, p.Type.CName)
+		}
+	}
+}
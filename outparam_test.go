@@ -0,0 +1,85 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestParameterDirections(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		comment string
+		want    map[string]gen.ParameterDirection
+	}{
+		"DirectionAfterName": {
+			comment: `/**
+ * Retrieve the file, line and column represented by the given source location.
+ *
+ * \param location the location within a source file that will be decomposed
+ * into its parts.
+ *
+ * \param file [out] if non-NULL, will be set to the file to which the given
+ * source location points.
+ *
+ * \param line [out] if non-NULL, will be set to the line to which the given
+ * source location points.
+ */`,
+			want: map[string]gen.ParameterDirection{
+				"location": gen.ParameterDirectionUnknown,
+				"file":     gen.ParameterDirectionOut,
+				"line":     gen.ParameterDirectionOut,
+			},
+		},
+		"DirectionBeforeName": {
+			comment: `/// \param[in] tu the translation unit.
+/// \param[in,out] options the options.
+/// \param[out] kind the kind.`,
+			want: map[string]gen.ParameterDirection{
+				"tu":      gen.ParameterDirectionIn,
+				"options": gen.ParameterDirectionInOut,
+				"kind":    gen.ParameterDirectionOut,
+			},
+		},
+		"WillBeSetTo": {
+			comment: `/**
+ * \param cursor the cursor.
+ *
+ * \param isGenerated if non-NULL, will be
+ * set to whether the declaration is generated.
+ *
+ * \returns the spelling.
+ */`,
+			want: map[string]gen.ParameterDirection{
+				"cursor":      gen.ParameterDirectionUnknown,
+				"isGenerated": gen.ParameterDirectionOut,
+			},
+		},
+		"ReturnsIsNotPartOfTheParameter": {
+			comment: `/**
+ * \param cursor the cursor.
+ * \returns the name which will be set to the cursor.
+ */`,
+			want: map[string]gen.ParameterDirection{
+				"cursor": gen.ParameterDirectionUnknown,
+			},
+		},
+		"NoParameters": {
+			comment: `/** Returns the version. */`,
+			want:    map[string]gen.ParameterDirection{},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, gen.ParameterDirections(tt.comment)); diff != "" {
+				t.Fatalf("ParameterDirections(): (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
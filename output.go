@@ -0,0 +1,142 @@
+package gen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/template"
+)
+
+// Output receives the files of a generation.
+type Output interface {
+	// WriteFile writes the generated file name, e.g. "cursor_gen.go", with the content data.
+	WriteFile(name string, data []byte) error
+}
+
+// OutputFunc is an adapter to allow the use of an ordinary function as Output.
+type OutputFunc func(name string, data []byte) error
+
+// WriteFile calls o(name, data).
+func (o OutputFunc) WriteFile(name string, data []byte) error {
+	return o(name, data)
+}
+
+// DirOutput writes the generated files into the directory it names.
+type DirOutput string
+
+// defaultOutput is the output of a generation if API.Output is not set.
+const defaultOutput = DirOutput("clang")
+
+// WriteFile writes data into the file name of the directory d.
+func (d DirOutput) WriteFile(name string, data []byte) error {
+	return os.WriteFile(d.path(name), data, 0600)
+}
+
+// path returns the path of the file name of the directory d.
+func (d DirOutput) path(name string) string {
+	return filepath.Join(string(d), name)
+}
+
+// pathOutput is implemented by outputs which write the files into the file system.
+type pathOutput interface {
+	// path returns the path which the generated file name is written to.
+	path(name string) string
+}
+
+// outputPath returns the path which the generated file name is written to by out, or name if out does not write into
+// the file system.
+func outputPath(out Output, name string) string {
+	if p, ok := out.(pathOutput); ok {
+		return p.path(name)
+	}
+
+	return name
+}
+
+// MemoryOutput collects the generated files in memory. It is safe for concurrent use.
+type MemoryOutput struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemoryOutput returns a new empty *MemoryOutput.
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{
+		files: make(map[string][]byte),
+	}
+}
+
+// WriteFile stores a copy of data as the file name, replacing an earlier file with the same name.
+func (m *MemoryOutput) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[name] = append([]byte(nil), data...)
+
+	return nil
+}
+
+// Files returns a copy of the collected files keyed by their names.
+func (m *MemoryOutput) Files() map[string][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	files := make(map[string][]byte, len(m.files))
+	for name, data := range m.files {
+		files[name] = append([]byte(nil), data...)
+	}
+
+	return files
+}
+
+// headerOutput writes the header of every file in front of it, see Header.
+type headerOutput struct {
+	out        Output
+	tmpl       *template.Template
+	constraint string
+}
+
+// WriteFile writes the header and data as the file name to the underlying Output.
+func (o headerOutput) WriteFile(name string, data []byte) error {
+	header, err := Header{Name: name, Constraint: o.constraint}.Generate(o.tmpl)
+	if err != nil {
+		return fmt.Errorf("cannot generate header of %s: %w", name, err)
+	}
+
+	return o.out.WriteFile(name, append(header, data...))
+}
+
+// path returns the path which the underlying Output writes the file name to.
+func (o headerOutput) path(name string) string {
+	return outputPath(o.out, name)
+}
+
+// output returns the output of the generation for files without a build constraint.
+func (g *Generation) output() Output {
+	return g.constrainedOutput("")
+}
+
+// constrainedOutput returns the output of the generation for files with the build constraint c.
+func (g *Generation) constrainedOutput(c string) Output {
+	out := g.api.Output
+	if out == nil {
+		out = defaultOutput
+	}
+
+	return headerOutput{
+		out:        out,
+		tmpl:       g.api.HeaderTemplate,
+		constraint: c,
+	}
+}
+
+// cgoOutput returns the output of the generation for files which need cgo, which are excluded from builds of the dlopen
+// backend without cgo.
+func (g *Generation) cgoOutput() Output {
+	if g.api.Backend == BackendDlopen {
+		return g.constrainedOutput("cgo")
+	}
+
+	return g.output()
+}
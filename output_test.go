@@ -0,0 +1,87 @@
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestMemoryOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		files map[string]string
+		want  map[string]string
+	}{
+		"empty": {
+			files: map[string]string{},
+			want:  map[string]string{},
+		},
+		"files": {
+			files: map[string]string{
+				"cursor_gen.go": "package clang",
+				"shims_gen.c":   "#include \"shims_gen.h\"",
+			},
+			want: map[string]string{
+				"cursor_gen.go": "package clang",
+				"shims_gen.c":   "#include \"shims_gen.h\"",
+			},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := gen.NewMemoryOutput()
+			for name, data := range tt.files {
+				b := []byte(data)
+				if err := out.WriteFile(name, b); err != nil {
+					t.Fatalf("WriteFile(%q) error = %v", name, err)
+				}
+				// the output must not alias the written data
+				copy(b, "XXXX")
+			}
+
+			got := make(map[string]string)
+			for name, data := range out.Files() {
+				got[name] = string(data)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("MemoryOutput: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileGenerateOutput(t *testing.T) {
+	t.Parallel()
+
+	f := gen.NewFile("cursorkind")
+	f.Enums = append(f.Enums, &gen.Enum{
+		IncludeFiles:   gen.NewIncludeFiles(),
+		Name:           "CursorKind",
+		CName:          "CXCursorKind",
+		UnderlyingType: "uint32",
+		Items: []gen.EnumItem{
+			{Name: "Cursor_UnexposedDecl", CName: "CXCursor_UnexposedDecl"},
+		},
+	})
+
+	out := gen.NewMemoryOutput()
+	if err := f.Generate(out, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	files := out.Files()
+	if len(files) != 1 {
+		t.Fatalf("Generate() wrote %d files, want 1", len(files))
+	}
+	if got := string(files["cursorkind_gen.go"]); !strings.Contains(got, "type CursorKind uint32") {
+		t.Fatalf("cursorkind_gen.go does not declare CursorKind:\n%s", got)
+	}
+}
@@ -0,0 +1,67 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ReportKind defines the kind of a ReportEntry.
+type ReportKind string
+
+const (
+	// ReportAmbiguous notes a symbol whose generation could not be decided from its declaration and documentation.
+	ReportAmbiguous ReportKind = "ambiguous"
+	// ReportAvailability notes the LLVM version which introduced a symbol.
+	ReportAvailability ReportKind = "availability"
+	// ReportRenamed notes a symbol which was renamed to resolve a conflict.
+	ReportRenamed ReportKind = "renamed"
+	// ReportSkipped notes a symbol which was not generated.
+	ReportSkipped ReportKind = "skipped"
+)
+
+// ReportEntry represents a single note of a generation Report.
+type ReportEntry struct {
+	Kind    ReportKind
+	Symbol  string
+	Message string
+}
+
+// Report collects notes about a generation which are not errors, e.g. the availability of symbols or skipped symbols.
+type Report struct {
+	Entries []ReportEntry
+}
+
+// Add adds a new entry for symbol to r.
+func (r *Report) Add(kind ReportKind, symbol string, format string, args ...interface{}) {
+	r.Entries = append(r.Entries, ReportEntry{
+		Kind:    kind,
+		Symbol:  symbol,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// WriteTo writes all entries of r sorted by their kind and symbol to w.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	entries := make([]ReportEntry, len(r.Entries))
+	copy(entries, r.Entries)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+
+		return entries[i].Symbol < entries[j].Symbol
+	})
+
+	var written int64
+	for _, e := range entries {
+		n, err := fmt.Fprintf(w, "%s: %s: %s\n", e.Kind, e.Symbol, e.Message)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
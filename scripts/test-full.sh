@@ -7,6 +7,10 @@ LLVM_VERSION=$(clang --version | grep --max-count=1 "clang version" | sed -r 's/
 # Test with the race detector
 CGO_LDFLAGS="-L`llvm-config --libdir`" go test -timeout 60s -v -race ./...
 
+# Test with the cgo pointer checks and checkptr instrumentation
+if [ $(go env GOVERSION | sed -r 's/^go1\.([0-9]+).*$/\1/') -ge 21 ]; then CGOCHECK_ENV="GOEXPERIMENT=cgocheck2"; else CGOCHECK_ENV="GODEBUG=cgocheck=2"; fi
+env $CGOCHECK_ENV CGO_LDFLAGS="-L`llvm-config --libdir`" go test -timeout 60s -v -gcflags=all=-d=checkptr ./...
+
 # Test with the address sanitizer
 # TODO there is maybe a problem within clang https://github.com/go-clang/gen/issues/123
 # if [ $(echo "$LLVM_VERSION>=3.9" | bc -l) -ne 0 ] && [ $(find `llvm-config --libdir` | grep libclang_rt.san-x86_64.a | wc -l) -ne 0 ]; then CGO_LDFLAGS="-L`llvm-config --libdir` -fsanitize=memory" CGO_CPPFLAGS='-fsanitize=memory -fsanitize-memory-track-origins -fno-omit-frame-pointer' go test -timeout 60s -v -msan ./...; fi
@@ -0,0 +1,171 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	shimPrefix = "go_clang_shim_"
+
+	shimsFilename = "shims_gen"
+)
+
+// Shim represents a C function which wraps a static or inline C function, since cgo can only call C functions which
+// are exported symbols.
+type Shim struct {
+	IncludeFiles IncludeFiles
+
+	Name  string
+	CName string
+
+	// Declaration holds the C declaration of the shim without a trailing semicolon
+	Declaration string
+	// Call holds the C call of the wrapped function including a return if the function returns a value
+	Call string
+}
+
+// cDeclaration returns the C declaration of name with the C type spelling typ, e.g. "int (*name)(void)" for
+// "int (*)(void)".
+func cDeclaration(typ string, name string) string {
+	if i := strings.Index(typ, "(*)"); i >= 0 {
+		return typ[:i+2] + name + typ[i+2:]
+	}
+	if i := strings.Index(typ, "["); i >= 0 {
+		return strings.TrimSpace(typ[:i]) + " " + name + typ[i:]
+	}
+	if strings.HasSuffix(typ, "*") {
+		return typ + name
+	}
+
+	return typ + " " + name
+}
+
+// NewShim returns the shim of the static or inline function f.
+func NewShim(f *Function) (*Shim, error) {
+	return newShim(shimPrefix+f.CName, f.CName, f)
+}
+
+// newShim returns a shim with the given name which calls the C function target with the signature of f.
+func newShim(name string, target string, f *Function) (*Shim, error) {
+	s := &Shim{
+		IncludeFiles: f.IncludeFiles,
+		Name:         name,
+		CName:        target,
+	}
+
+	if f.ReturnType.CName == "" {
+		return nil, fmt.Errorf("unknown C return type of %q", f.CName)
+	}
+	if f.ReturnType.IsFunctionPointer || f.ReturnType.IsArray {
+		return nil, fmt.Errorf("return type %q of %q is not supported", f.ReturnType.CName, f.CName)
+	}
+
+	parameters := make([]string, len(f.Parameters))
+	arguments := make([]string, len(f.Parameters))
+	for i, p := range f.Parameters {
+		if p.Type.CName == "" {
+			return nil, fmt.Errorf("unknown C type of parameter %q of %q", p.CName, f.CName)
+		}
+
+		// the parameters are numbered since their C names may have been replaced
+		name := fmt.Sprintf("p%d", i)
+		parameters[i] = cDeclaration(p.Type.CName, name)
+		arguments[i] = name
+	}
+	if len(parameters) == 0 {
+		parameters = []string{"void"}
+	}
+
+	s.Declaration = cDeclaration(f.ReturnType.CName, s.Name) + "(" + strings.Join(parameters, ", ") + ")"
+	s.Call = target + "(" + strings.Join(arguments, ", ") + ");"
+	if !(f.ReturnType.GoName == "void" && f.ReturnType.PointerLevel == 0) {
+		s.Call = "return " + s.Call
+	}
+
+	return s, nil
+}
+
+var templateGenerateShimsHeaderFile = template.Must(template.New("go-clang-generate-shims-header-file").Parse(`#ifndef GO_CLANG_SHIMS
+#define GO_CLANG_SHIMS
+
+{{range $h := $.IncludeFiles}}#include "{{$h}}"
+{{end}}
+{{range $s := $.Shims}}{{$s.Declaration}};
+{{end}}
+#endif
+`))
+
+var templateGenerateShimsFile = template.Must(template.New("go-clang-generate-shims-file").Parse(`#include "` + shimsFilename + `.h"
+{{range $s := $.Shims}}
+{{$s.Declaration}} {
+	{{$s.Call}}
+}
+{{end}}`))
+
+// addShim makes f call a generated shim instead of the C function itself.
+func (g *Generation) addShim(f *Function) error {
+	s, err := NewShim(f)
+	if err != nil {
+		return err
+	}
+
+	g.useShim(f, s)
+
+	return nil
+}
+
+// useShim adds s to the generated shims and makes f call it.
+func (g *Generation) useShim(f *Function, s *Shim) {
+	g.shims = append(g.shims, s)
+
+	f.Shim = s.Name
+	f.IncludeFiles.AddIncludeFile("./clang/" + shimsFilename + ".h")
+}
+
+// GenerateShims generates a C header and a C file which define the shims of all static and inline functions.
+func (g *Generation) GenerateShims() error {
+	includes := NewIncludeFiles()
+	for _, s := range g.shims {
+		for h := range s.IncludeFiles {
+			// the shims are generated into the clang directory
+			if h != "./clang/"+shimsFilename+".h" {
+				includes.AddIncludeFile(strings.TrimPrefix(h, "./clang/"))
+			}
+		}
+	}
+
+	data := struct {
+		IncludeFiles []string
+		Shims        []*Shim
+	}{
+		Shims: g.shims,
+	}
+	for h := range includes {
+		data.IncludeFiles = append(data.IncludeFiles, h)
+	}
+	sort.Strings(data.IncludeFiles)
+
+	for _, f := range []struct {
+		name string
+		tmpl *template.Template
+		out  Output
+	}{
+		{shimsFilename + ".h", templateGenerateShimsHeaderFile, g.output()},
+		{shimsFilename + ".c", templateGenerateShimsFile, g.cgoOutput()},
+	} {
+		var b bytes.Buffer
+		if err := f.tmpl.Execute(&b, data); err != nil {
+			return err
+		}
+
+		if err := f.out.WriteFile(f.name, b.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
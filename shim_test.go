@@ -0,0 +1,67 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/go-clang/gen"
+)
+
+func TestNewShim(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		f               *gen.Function
+		wantDeclaration string
+		wantCall        string
+	}{
+		"Void": {
+			f: &gen.Function{
+				CName:      "reset",
+				ReturnType: gen.Type{CName: "void", GoName: "void"},
+			},
+			wantDeclaration: "void go_clang_shim_reset(void)",
+			wantCall:        "reset();",
+		},
+		"Parameters": {
+			f: &gen.Function{
+				CName: "lib_name",
+				Parameters: []gen.FunctionParameter{
+					{CName: "cursor", Type: gen.Type{CName: "CXCursor", GoName: "Cursor"}},
+					{CName: "buf", Type: gen.Type{CName: "const char *", GoName: gen.GoInt8, PointerLevel: 1}},
+				},
+				ReturnType: gen.Type{CName: "unsigned int", GoName: gen.GoUInt32},
+			},
+			wantDeclaration: "unsigned int go_clang_shim_lib_name(CXCursor p0, const char *p1)",
+			wantCall:        "return lib_name(p0, p1);",
+		},
+		"FunctionPointer": {
+			f: &gen.Function{
+				CName: "lib_visit",
+				Parameters: []gen.FunctionParameter{
+					{CName: "visitor", Type: gen.Type{CName: "int (*)(void *)", IsFunctionPointer: true}},
+				},
+				ReturnType: gen.Type{CName: "void *", GoName: "void", PointerLevel: 1},
+			},
+			wantDeclaration: "void *go_clang_shim_lib_visit(int (*p0)(void *))",
+			wantCall:        "return lib_visit(p0);",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := gen.NewShim(tt.f)
+			if err != nil {
+				t.Fatalf("NewShim() error = %v", err)
+			}
+
+			if s.Declaration != tt.wantDeclaration {
+				t.Errorf("Shim.Declaration = %q, want %q", s.Declaration, tt.wantDeclaration)
+			}
+			if s.Call != tt.wantCall {
+				t.Errorf("Shim.Call = %q, want %q", s.Call, tt.wantCall)
+			}
+		})
+	}
+}
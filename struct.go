@@ -1,8 +1,8 @@
 package gen
 
 import (
-	"fmt"
 	"strings"
+	"text/template"
 
 	"github.com/go-clang/bootstrap/clang"
 )
@@ -20,9 +20,13 @@ type Struct struct {
 	Comment        string
 
 	IsPointerComposition bool
+	// IsPointerTypedef whether the C type is a typedef of a pointer, e.g. an opaque handle like CXIndex
+	IsPointerTypedef bool
 
-	Fields  []*StructField
-	Methods []interface{}
+	Fields []*StructField
+	// Callbacks holds the function pointer fields of the struct.
+	Callbacks []*StructCallback
+	Methods   []interface{}
 }
 
 // StructField field of Struct.
@@ -30,10 +34,74 @@ type StructField struct {
 	CName   string
 	Comment string
 	Type    Type
+	// Since holds the LLVM version which introduced the field, if known.
+	Since string
+	// BitWidth holds the width in bits if the field is a bitfield, otherwise 0.
+	BitWidth int
+}
+
+// StructCallback represents a function pointer field of a Struct.
+type StructCallback struct {
+	Name       string
+	CName      string
+	Comment    string
+	Parameters []FunctionParameter
+	ReturnType Type
+}
+
+// handleStructCallbackCursor handles the function pointer field cursor and returns the new *StructCallback, or nil if
+// the function type of the field cannot be determined.
+func handleStructCallbackCursor(cursor clang.Cursor) *StructCallback {
+	proto := cursor.Type().PointeeType()
+	if proto.Kind() != clang.Type_FunctionProto {
+		proto = proto.CanonicalType()
+	}
+	if proto.Kind() != clang.Type_FunctionProto {
+		return nil
+	}
+
+	cb := &StructCallback{
+		Name:  UpperFirstCharacter(cursor.DisplayName()),
+		CName: cursor.DisplayName(),
+	}
+	cb.Comment = CleanDoxygenComment(cb.Name, cursor.RawCommentText())
+
+	typ, err := TypeFromClangType(proto.ResultType())
+	if err != nil {
+		return nil
+	}
+	cb.ReturnType = typ
+
+	var names []string
+	for c := range cursorChildren(cursor) {
+		if c.Kind() == clang.Cursor_ParmDecl {
+			names = append(names, c.DisplayName())
+		}
+	}
+
+	numArgs := int(proto.NumArgTypes())
+	for i := 0; i < numArgs; i++ {
+		typ, err := TypeFromClangType(proto.ArgType(uint32(i)))
+		if err != nil {
+			return nil
+		}
+
+		p := FunctionParameter{
+			Type: typ,
+		}
+		if len(names) == numArgs {
+			p.CName = names[i]
+		}
+		p.Name = ParameterName(p.CName, p.Type)
+
+		cb.Parameters = append(cb.Parameters, p)
+	}
+
+	return cb
 }
 
 // HandleStructCursor handles the struct cursor.
-func HandleStructCursor(cursor clang.Cursor, cname string, cnameIsTypeDef bool) *Struct {
+func HandleStructCursor(cursor clang.Cursor, cname string, cnameIsTypeDef bool) (*Struct, error) {
 	s := &Struct{
 		IncludeFiles:   NewIncludeFiles(),
 		Name:           TrimLanguagePrefix(cname),
@@ -43,16 +111,20 @@ func HandleStructCursor(cursor clang.Cursor, cname string, cnameIsTypeDef bool)
 	s.Comment = CleanDoxygenComment(s.Name, cursor.RawCommentText())
 	s.Receiver.Name = CommonReceiverName(s.Name)
 
-	cursor.Visit(func(cursor, _ clang.Cursor) clang.ChildVisitResult {
+	for cursor := range cursorChildren(cursor) {
 		switch cursor.Kind() {
 		case clang.Cursor_FieldDecl:
 			typ, err := TypeFromClangType(cursor.Type())
 			if err != nil {
-				panic(fmt.Errorf("unexpected error: %w, cursor.Type(): %#v", err, cursor.Type()))
+				return nil, newCursorError(cursor, cname+"."+cursor.DisplayName(), "cannot handle field type %q: %w", cursor.Type().Spelling(), err)
 			}
 
 			if typ.IsFunctionPointer {
-				return clang.ChildVisit_Continue
+				if cb := handleStructCallbackCursor(cursor); cb != nil {
+					s.Callbacks = append(s.Callbacks, cb)
+				}
+
+				continue
 			}
 
 			field := &StructField{
@@ -60,13 +132,14 @@ func HandleStructCursor(cursor clang.Cursor, cname string, cnameIsTypeDef bool)
 				Type:  typ,
 			}
 			field.Comment = CleanDoxygenComment(TrimCommonFunctionName(field.CName, typ), cursor.RawCommentText())
+			if cursor.IsBitField() {
+				field.BitWidth = int(cursor.FieldDeclBitWidth())
+			}
 			s.Fields = append(s.Fields, field)
 		}
+	}
 
-		return clang.ChildVisit_Continue
-	})
-
-	return s
+	return s, nil
 }
 
 // ContainsMethod reports whether the contains name in Struct.
@@ -88,12 +161,18 @@ func (s *Struct) ContainsMethod(name string) bool {
 	return false
 }
 
-// Generate generates the struct.
-func (s *Struct) Generate() error {
+// File returns the generation file of the struct.
+func (s *Struct) File() *File {
 	f := NewFile(strings.ToLower(s.Name))
 	f.Structs = append(f.Structs, s)
 
-	return f.Generate()
+	return f
+}
+
+// Generate generates the struct with the file template tmpl and writes it to out. DefaultFileTemplate is used if tmpl is
+// nil.
+func (s *Struct) Generate(out Output, tmpl *template.Template) error {
+	return s.File().Generate(out, tmpl)
 }
 
 // AddFieldGetters adds field getters to s.
@@ -116,6 +195,11 @@ func (s *Struct) AddFieldGetters() error {
 			continue
 		}
 
+		// bitfields cannot be addressed by cgo, see Generation.AddBitFieldGetters
+		if m.BitWidth > 0 {
+			continue
+		}
+
 		f := NewFunction(m.CName, s.CName, m.Comment, m.CName, m.Type)
 
 		if !s.ContainsMethod(f.Name) {
@@ -0,0 +1,2 @@
+#define CINDEX_VERSION_MAJOR 0
+#define CINDEX_VERSION_MINOR 62
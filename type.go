@@ -81,8 +81,50 @@ type Type struct {
 	// IsSlice whether the this Type is slice
 	IsSlice bool
 
+	// IsCopiedToC whether the elements of this slice Type are copied into C memory before they are passed to C
+	IsCopiedToC bool
+
+	// IsCopiedSlice whether the elements of this slice Type are copied from C memory into Go memory
+	IsCopiedSlice bool
+
 	// IsPointerComposition whether the this Type is pointer composition
 	IsPointerComposition bool
+
+	// IsNullable whether the this Type is returned together with whether its C pointer is not NULL
+	IsNullable bool
+
+	// IsConst holds whether the value is const qualified for every pointer level as bitmask, bit 0 is the value itself
+	// and bit PointerLevel is the value after dereferencing all pointers, e.g. 0b10 for "const char *"
+	IsConst uint64
+
+	// Ownership ownership of the C memory which is referenced by this Type
+	Ownership Ownership
+
+	// DisposedBy name of the C function which disposes the memory if Ownership is OwnershipDisposedBy
+	DisposedBy string
+}
+
+// Ownership defines who owns C memory which is returned by a function.
+type Ownership int
+
+const (
+	// OwnershipUnknown derives the ownership from the const-ness of the type.
+	OwnershipUnknown Ownership = iota
+	// OwnershipBorrowed the memory is owned by libclang and must not be released by the caller.
+	OwnershipBorrowed
+	// OwnershipCallerOwned the memory is owned by the caller and must be released with free.
+	OwnershipCallerOwned
+	// OwnershipDisposedBy the memory is owned by the caller and must be released with the function Type.DisposedBy.
+	OwnershipDisposedBy
+)
+
+// IsConstAt reports whether the value of typ after dereferencing level pointers is const qualified.
+func (t Type) IsConstAt(level int) bool {
+	if level < 0 || level >= 64 {
+		return false
+	}
+
+	return t.IsConst&(1<<level) != 0
 }
 
 // TypeFromClangType returns the Type from Clang type.
@@ -97,6 +139,10 @@ func TypeFromClangType(cType clang.Type) (Type, error) {
 		IsFunctionPointer: false,
 	}
 
+	if cType.IsConstQualifiedType() {
+		typ.IsConst = 1
+	}
+
 	switch cType.Kind() {
 	case clang.Type_Char_S:
 		typ.CGoName = CSChar
@@ -163,6 +209,7 @@ func TypeFromClangType(cType clang.Type) (Type, error) {
 		typ.CGoName = subTyp.CGoName
 		typ.GoName = subTyp.GoName
 		typ.PointerLevel += subTyp.PointerLevel
+		typ.IsConst = subTyp.IsConst
 		typ.IsArray = true
 		typ.ArraySize = cType.ArraySize()
 
@@ -207,6 +254,7 @@ func TypeFromClangType(cType clang.Type) (Type, error) {
 		typ.GoName = subTyp.GoName
 		typ.PointerLevel += subTyp.PointerLevel
 		typ.IsPrimitive = subTyp.IsPrimitive
+		typ.IsConst |= subTyp.IsConst << 1
 
 	case clang.Type_Record:
 		typ.CGoName = cType.Declaration().Type().Spelling()
@@ -236,6 +284,7 @@ func TypeFromClangType(cType clang.Type) (Type, error) {
 		typ.GoName = subTyp.GoName
 		typ.PointerLevel += subTyp.PointerLevel
 		typ.IsPrimitive = subTyp.IsPrimitive
+		typ.IsConst |= subTyp.IsConst
 
 	default:
 		return Type{}, fmt.Errorf("unhandled type %q of kind %q", cType.Spelling(), cType.Kind().Spelling())
@@ -0,0 +1,38 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/go-clang/gen"
+)
+
+func TestTypeIsConstAt(t *testing.T) {
+	t.Parallel()
+
+	// const char *const *
+	typ := gen.Type{
+		PointerLevel: 2,
+		IsConst:      0b110,
+	}
+
+	tests := map[string]struct {
+		level int
+		want  bool
+	}{
+		"Value":         {level: 0, want: false},
+		"Pointee":       {level: 1, want: true},
+		"Chars":         {level: 2, want: true},
+		"OutOfRange":    {level: 3, want: false},
+		"NegativeLevel": {level: -1, want: false},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := typ.IsConstAt(tt.level); got != tt.want {
+				t.Fatalf("Type.IsConstAt(%d) = %t, want %t", tt.level, got, tt.want)
+			}
+		})
+	}
+}
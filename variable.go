@@ -0,0 +1,174 @@
+package gen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-clang/bootstrap/clang"
+)
+
+// Variable represents a generation global variable.
+type Variable struct {
+	IncludeFiles IncludeFiles
+
+	Name    string
+	CName   string
+	Comment string
+
+	Type Type
+
+	// IsExtern whether the variable is declared with external storage
+	IsExtern bool
+
+	// Value holds the Go literal of the initializer if it is a compile-time value of a const variable
+	Value string
+
+	// TypeError holds why the type of the variable is not supported, the variable is not generated then
+	TypeError error
+}
+
+// HandleVariableCursor handles the global variable cursor and returns the new *Variable. Variable.TypeError is set if
+// its type is not supported.
+func HandleVariableCursor(cursor clang.Cursor) *Variable {
+	v := &Variable{
+		IncludeFiles: NewIncludeFiles(),
+		Name:         UpperFirstCharacter(TrimLanguagePrefix(strings.TrimPrefix(cursor.Spelling(), "clang_"))),
+		CName:        cursor.Spelling(),
+		IsExtern:     cursor.HasVarDeclExternalStorage(),
+	}
+	v.Comment = CleanDoxygenComment(v.Name, cursor.RawCommentText())
+
+	typ, err := TypeFromClangType(cursor.Type())
+	if err != nil {
+		v.TypeError = err
+
+		return v
+	}
+	v.Type = typ
+
+	if v.Type.IsConstAt(0) {
+		v.Value = evaluateVariableCursor(cursor, v.Type)
+	}
+
+	return v
+}
+
+// evaluateVariableCursor returns the Go literal of the initializer of the variable cursor with the type typ, or an
+// empty string if it is not a compile-time value.
+func evaluateVariableCursor(cursor clang.Cursor, typ Type) string {
+	res := cursor.Evaluate()
+	defer res.Dispose()
+
+	switch res.Kind() {
+	case clang.Eval_Int:
+		if typ.GoName == GoBool {
+			return strconv.FormatBool(res.AsLongLong() != 0)
+		}
+		if res.IsUnsignedInt() {
+			return strconv.FormatUint(res.AsUnsigned(), 10)
+		}
+
+		return strconv.FormatInt(res.AsLongLong(), 10)
+
+	case clang.Eval_Float:
+		return strconv.FormatFloat(res.AsDouble(), 'g', -1, 64)
+
+	case clang.Eval_StrLiteral:
+		if typ.PointerLevel == 1 && typ.CGoName == CSChar {
+			return strconv.Quote(res.AsStr())
+		}
+	}
+
+	return ""
+}
+
+// IsConstant reports whether v is generated as Go constant.
+func (v *Variable) IsConstant() bool {
+	return v.Value != ""
+}
+
+// GenerateConstant generates the Go constant declaration of v.
+func (v *Variable) GenerateConstant() string {
+	var b strings.Builder
+	if v.Comment != "" {
+		b.WriteString(v.Comment + "\n")
+	}
+
+	b.WriteString("const " + v.Name)
+	if v.Type.PointerLevel == 0 && v.Type.GoName != GoBool && v.Type.GoName != "" {
+		b.WriteString(" " + v.Type.GoName)
+	}
+	b.WriteString(" = " + v.Value)
+
+	return b.String()
+}
+
+// Getter returns the function which reads v.
+func (v *Variable) Getter() *Function {
+	return &Function{
+		IncludeFiles: v.IncludeFiles,
+		Name:         v.Name,
+		CName:        v.CName,
+		Comment:      v.Comment,
+		Parameters:   []FunctionParameter{},
+		ReturnType:   v.Type,
+		Variable:     v,
+	}
+}
+
+// IsSettable reports whether v is mutable and its type can be assigned from Go.
+func (v *Variable) IsSettable() bool {
+	return !v.Type.IsConstAt(0) && v.Type.PointerLevel == 0 && !v.Type.IsArray && v.Type.GoName != GoBool && v.Type.GoName != "cxstring" && v.Type.GoName != "time.Time"
+}
+
+// GenerateSetter generates the function which assigns v. The value is cast for primitives and enums, structs are
+// assigned by their C value.
+func (v *Variable) GenerateSetter() string {
+	value := "C." + v.Type.CGoName + "(value)"
+	if !v.Type.IsPrimitive && !v.Type.IsEnumLiteral {
+		value = "value.c"
+	}
+
+	return fmt.Sprintf("// Set%s sets the value of %s.\nfunc Set%s(value %s) {\n\tC.%s = %s\n}", v.Name, v.Name, v.Name, v.Type.GoName, v.CName, value)
+}
+
+// addVariables adds the constants and accessors of all global variables to clangFile. Variables which cannot be
+// accessed from Go are added to the report.
+func (g *Generation) addVariables(clangFile *File) {
+	for _, v := range g.variables {
+		if v.TypeError != nil {
+			g.report.Add(ReportSkipped, v.CName, "global variable of unsupported type: %v", v.TypeError)
+
+			continue
+		}
+
+		// prepare the type like a return type of a function
+		if n, ok := g.LookupNonTypedef(v.Type.CGoName); ok {
+			v.Type.GoName = n
+		}
+		if e, ok := g.HasEnum(v.Type.GoName); ok {
+			v.Type.CGoName = e.Receiver.Type.CGoName
+		}
+		g.SetIsPointerComposition(&v.Type)
+
+		switch {
+		case v.IsConstant():
+			clangFile.Functions = append(clangFile.Functions, v.GenerateConstant())
+
+		case !v.IsExtern:
+			g.report.Add(ReportSkipped, v.CName, "global variable without external storage and compile-time value")
+
+		case v.Type.IsArray || v.Type.IsFunctionPointer || (v.Type.PointerLevel > 0 && v.Type.GoName == "void"):
+			g.report.Add(ReportSkipped, v.CName, "global variable of type %q is not supported", v.Type.CName)
+
+		default:
+			clangFile.IncludeFiles.unifyIncludeFiles(v.IncludeFiles)
+			clangFile.Functions = append(clangFile.Functions, v.Getter())
+
+			if v.IsSettable() {
+				clangFile.Functions = append(clangFile.Functions, v.GenerateSetter())
+			}
+		}
+	}
+}
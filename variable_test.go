@@ -0,0 +1,132 @@
+package gen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestVariableGenerateConstant(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		v    *gen.Variable
+		want string
+	}{
+		"Integer": {
+			v: &gen.Variable{
+				Name:  "DefaultOptions",
+				Type:  gen.Type{GoName: gen.GoUInt32},
+				Value: "4",
+			},
+			want: "const DefaultOptions uint32 = 4",
+		},
+		"Bool": {
+			v: &gen.Variable{
+				Name:  "Enabled",
+				Type:  gen.Type{GoName: gen.GoBool},
+				Value: "true",
+			},
+			want: "const Enabled = true",
+		},
+		"String": {
+			v: &gen.Variable{
+				Name:    "Version",
+				Comment: "// Version of the library.",
+				Type:    gen.Type{GoName: gen.GoInt8, CGoName: gen.CSChar, PointerLevel: 1},
+				Value:   `"1.0"`,
+			},
+			want: "// Version of the library.\nconst Version = \"1.0\"",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.v.GenerateConstant(); got != tt.want {
+				t.Fatalf("Variable.GenerateConstant() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariableGenerateSetter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		v    *gen.Variable
+		want string
+	}{
+		"Primitive": {
+			v: &gen.Variable{
+				Name:  "Verbosity",
+				CName: "verbosity",
+				Type:  gen.Type{GoName: gen.GoInt32, CGoName: gen.CInt, IsPrimitive: true},
+			},
+			want: "// SetVerbosity sets the value of Verbosity.\nfunc SetVerbosity(value int32) {\n\tC.verbosity = C.int(value)\n}",
+		},
+		"Struct": {
+			v: &gen.Variable{
+				Name:  "DefaultRange",
+				CName: "default_range",
+				Type:  gen.Type{GoName: "SourceRange", CGoName: "CXSourceRange"},
+			},
+			want: "// SetDefaultRange sets the value of DefaultRange.\nfunc SetDefaultRange(value SourceRange) {\n\tC.default_range = value.c\n}",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if !tt.v.IsSettable() {
+				t.Fatalf("Variable.IsSettable() = false, want true")
+			}
+			if got := tt.v.GenerateSetter(); got != tt.want {
+				t.Fatalf("Variable.GenerateSetter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneration_AddVariables(t *testing.T) {
+	t.Parallel()
+
+	api := &gen.API{}
+	h := gen.NewHeaderFile(api, "Index.h", "testdata")
+	h.Variables = []*gen.Variable{
+		{
+			Name:      "Handler",
+			CName:     "clang_handler",
+			IsExtern:  true,
+			TypeError: errors.New(`unhandled type "struct Handler" of kind "Record"`),
+		},
+		{
+			Name:  "Counter",
+			CName: "clang_counter",
+			Type:  gen.Type{GoName: gen.GoInt32, CGoName: gen.CInt, IsPrimitive: true},
+		},
+	}
+
+	g := gen.NewGeneration(api)
+	g.AddHeaderFiles([]*gen.HeaderFile{h})
+
+	f := gen.NewFile("clang")
+	g.AddVariables(f)
+
+	if len(f.Functions) != 0 {
+		t.Fatalf("AddVariables() added %d functions, want none", len(f.Functions))
+	}
+
+	want := []gen.ReportEntry{
+		{Kind: gen.ReportSkipped, Symbol: "clang_handler", Message: `global variable of unsupported type: unhandled type "struct Handler" of kind "Record"`},
+		{Kind: gen.ReportSkipped, Symbol: "clang_counter", Message: "global variable without external storage and compile-time value"},
+	}
+	if diff := cmp.Diff(want, g.Report().Entries); diff != "" {
+		t.Fatalf("Report().Entries: (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,158 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VariadicSignature declares a fixed-arity call signature of a variadic C function for which a shim is generated.
+type VariadicSignature struct {
+	// Name holds the name of the fixed-arity function, which is processed like the name of a C function, e.g.
+	// "clang_logInt".
+	Name string
+
+	// Arguments holds the C types of the variadic arguments, e.g. []string{"int", "const char *"}.
+	Arguments []string
+}
+
+// cSpellingTypes maps C spellings of primitive types to their cgo and Go type names.
+var cSpellingTypes = map[string][2]string{
+	"char":               {CSChar, GoInt8},
+	"signed char":        {CSChar, GoInt8},
+	"unsigned char":      {CUChar, GoUInt8},
+	"short":              {CShort, GoInt16},
+	"unsigned short":     {CUShort, GoUInt16},
+	"int":                {CInt, GoInt32},
+	"unsigned":           {CUInt, GoUInt32},
+	"unsigned int":       {CUInt, GoUInt32},
+	"long":               {CLongInt, GoInt64},
+	"unsigned long":      {CULongInt, GoUInt64},
+	"long long":          {CLongLong, GoInt64},
+	"unsigned long long": {CULongLong, GoUInt64},
+	"float":              {CFloat, GoFloat32},
+	"double":             {CDouble, GoFloat64},
+	"void":               {"void", "void"},
+}
+
+// TypeFromCSpelling returns the Type of the C type spelling, e.g. "const char *". Only primitives, named types and
+// pointers to them are supported. A const qualifier applies to the pointer level it follows, or to the pointed-to type
+// if it precedes the first "*", e.g. "char const *" and "const char * const".
+func TypeFromCSpelling(spelling string) (Type, error) {
+	spelling = strings.Join(strings.Fields(spelling), " ")
+
+	typ := Type{
+		CName:        spelling,
+		ArraySize:    -1,
+		PointerLevel: strings.Count(spelling, "*"),
+		IsPrimitive:  true,
+	}
+	// the pointed-to type has the highest index, every "*" adds a pointer level which is dereferenced before it
+	level := typ.PointerLevel
+	var words []string
+	for _, token := range strings.Fields(strings.ReplaceAll(spelling, "*", " * ")) {
+		switch {
+		case token == "const":
+			typ.IsConst |= 1 << level
+
+		case token == "*":
+			level--
+
+		case level == typ.PointerLevel:
+			words = append(words, token)
+
+		default:
+			return Type{}, fmt.Errorf("unsupported C type %q", spelling)
+		}
+	}
+	base := strings.Join(words, " ")
+
+	if ct, ok := cSpellingTypes[base]; ok {
+		typ.CGoName = ct[0]
+		typ.GoName = ct[1]
+
+		return typ, nil
+	}
+
+	name := strings.TrimPrefix(base, "struct ")
+	if name == "" || strings.ContainsAny(name, " ()[]") {
+		return Type{}, fmt.Errorf("unsupported C type %q", spelling)
+	}
+
+	typ.IsPrimitive = false
+	typ.CGoName = name
+	if name != base {
+		typ.CGoName = "struct_" + name
+	}
+	typ.GoName = TrimLanguagePrefix(name)
+
+	return typ, nil
+}
+
+// expandVariadicFunctions replaces every variadic function by fixed-arity functions for the signatures declared in
+// API.VariadicShims. Variadic functions without signatures are skipped and added to the report since cgo cannot call
+// them.
+func (g *Generation) expandVariadicFunctions() {
+	functions := make([]*Function, 0, len(g.functions))
+
+	for _, f := range g.functions {
+		if !f.IsVariadic {
+			functions = append(functions, f)
+
+			continue
+		}
+
+		signatures := g.api.VariadicShims[f.CName]
+		if len(signatures) == 0 {
+			g.report.Add(ReportSkipped, f.CName, "variadic functions cannot be called through cgo, declare a fixed-arity signature in API.VariadicShims")
+
+			continue
+		}
+
+		for _, sig := range signatures {
+			vf, err := g.newVariadicFunction(f, sig)
+			if err != nil {
+				g.report.Add(ReportSkipped, sig.Name, "cannot generate fixed-arity function of %q: %v", f.CName, err)
+
+				continue
+			}
+
+			functions = append(functions, vf)
+		}
+	}
+
+	g.functions = functions
+}
+
+// newVariadicFunction returns the fixed-arity function of the variadic function f for the signature sig, which calls f
+// through a shim.
+func (g *Generation) newVariadicFunction(f *Function, sig VariadicSignature) (*Function, error) {
+	vf := *f
+	vf.Name = sig.Name
+	vf.CName = sig.Name
+	vf.IsVariadic = false
+	vf.IsInline = false
+	vf.IncludeFiles = NewIncludeFiles()
+	vf.IncludeFiles.unifyIncludeFiles(f.IncludeFiles)
+	vf.Parameters = append([]FunctionParameter{}, f.Parameters...)
+
+	for i, a := range sig.Arguments {
+		typ, err := TypeFromCSpelling(a)
+		if err != nil {
+			return nil, err
+		}
+
+		vf.Parameters = append(vf.Parameters, FunctionParameter{
+			Name:  fmt.Sprintf("arg%d", i+1),
+			CName: fmt.Sprintf("arg%d", i+1),
+			Type:  typ,
+		})
+	}
+
+	s, err := newShim(shimPrefix+sig.Name, f.CName, &vf)
+	if err != nil {
+		return nil, err
+	}
+	g.useShim(&vf, s)
+
+	return &vf, nil
+}
@@ -0,0 +1,74 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-clang/gen"
+)
+
+func TestTypeFromCSpelling(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		spelling string
+		want     gen.Type
+		wantErr  bool
+	}{
+		"int": {
+			spelling: "int",
+			want:     gen.Type{CName: "int", CGoName: gen.CInt, GoName: gen.GoInt32, ArraySize: -1, IsPrimitive: true},
+		},
+		"unsigned long": {
+			spelling: "unsigned  long",
+			want:     gen.Type{CName: "unsigned long", CGoName: gen.CULongInt, GoName: gen.GoUInt64, ArraySize: -1, IsPrimitive: true},
+		},
+		"string": {
+			spelling: "const char *",
+			want:     gen.Type{CName: "const char *", CGoName: gen.CSChar, GoName: gen.GoInt8, ArraySize: -1, PointerLevel: 1, IsPrimitive: true, IsConst: 0b10},
+		},
+		"const pointer to string": {
+			spelling: "const char * const",
+			want:     gen.Type{CName: "const char * const", CGoName: gen.CSChar, GoName: gen.GoInt8, ArraySize: -1, PointerLevel: 1, IsPrimitive: true, IsConst: 0b11},
+		},
+		"trailing const": {
+			spelling: "char const *",
+			want:     gen.Type{CName: "char const *", CGoName: gen.CSChar, GoName: gen.GoInt8, ArraySize: -1, PointerLevel: 1, IsPrimitive: true, IsConst: 0b10},
+		},
+		"pointer to const pointer": {
+			spelling: "char *const*",
+			want:     gen.Type{CName: "char *const*", CGoName: gen.CSChar, GoName: gen.GoInt8, ArraySize: -1, PointerLevel: 2, IsPrimitive: true, IsConst: 0b10},
+		},
+		"typedef": {
+			spelling: "CXCursor",
+			want:     gen.Type{CName: "CXCursor", CGoName: "CXCursor", GoName: "Cursor", ArraySize: -1},
+		},
+		"struct pointer": {
+			spelling: "struct CXUnsavedFile *",
+			want:     gen.Type{CName: "struct CXUnsavedFile *", CGoName: "struct_CXUnsavedFile", GoName: "UnsavedFile", ArraySize: -1, PointerLevel: 1},
+		},
+		"function pointer": {
+			spelling: "void (*)(int)",
+			wantErr:  true,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := gen.TypeFromCSpelling(tt.spelling)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TypeFromCSpelling(%q) error = %v, wantErr %t", tt.spelling, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("TypeFromCSpelling(%q): (-want +got):\n%s", tt.spelling, diff)
+			}
+		})
+	}
+}